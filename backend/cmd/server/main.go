@@ -14,13 +14,19 @@ import (
 	"github.com/your-org/ems/internal/activity"
 	"github.com/your-org/ems/internal/ai"
 	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/calendar"
+	"github.com/your-org/ems/internal/collection"
 	"github.com/your-org/ems/internal/config"
 	"github.com/your-org/ems/internal/db"
+	"github.com/your-org/ems/internal/embedding"
+	"github.com/your-org/ems/internal/goal"
 	"github.com/your-org/ems/internal/httputil"
 	"github.com/your-org/ems/internal/media"
 	"github.com/your-org/ems/internal/metadata"
 	"github.com/your-org/ems/internal/profile"
+	"github.com/your-org/ems/internal/review"
 	"github.com/your-org/ems/internal/search"
+	"github.com/your-org/ems/internal/searchlog"
 )
 
 func main() {
@@ -50,17 +56,61 @@ func main() {
 	authHandler := auth.NewHandler(authSvc)
 
 	// Metadata providers
-	tmdbClient := metadata.NewTMDBClient(cfg.TMDBAPIKey)
-	mbClient := metadata.NewMusicBrainzClient()
-	igdbClient := metadata.NewIGDBClient(cfg.IGDBClientID, cfg.IGDBClientSecret)
-	metaSvc := metadata.NewService(tmdbClient, mbClient, igdbClient)
-	metaHandler := metadata.NewHandler(metaSvc)
+	clientConfig := func(rateLimit float64, timeout time.Duration) metadata.ClientConfig {
+		return metadata.ClientConfig{
+			RateLimit:        rateLimit,
+			Timeout:          timeout,
+			MaxRetries:       cfg.MetadataMaxRetries,
+			BreakerThreshold: cfg.MetadataBreakerThreshold,
+			BreakerCooldown:  cfg.MetadataBreakerCooldown,
+		}
+	}
+	tmdbClient := metadata.NewTMDBClient(cfg.TMDBAPIKey, clientConfig(cfg.TMDBRateLimit, cfg.TMDBTimeout))
+	mbClient := metadata.NewMusicBrainzClient(clientConfig(cfg.MusicBrainzRateLimit, cfg.MusicBrainzTimeout))
+	igdbClient := metadata.NewIGDBClient(cfg.IGDBClientID, cfg.IGDBClientSecret, clientConfig(cfg.IGDBRateLimit, cfg.IGDBTimeout))
+	upcClient := metadata.NewUPCItemDBClient(cfg.UPCItemDBAPIKey, clientConfig(cfg.UPCItemDBRateLimit, cfg.UPCItemDBTimeout))
+	providerChains := make(map[media.MediaType][]string, len(cfg.MetadataProviders))
+	for mediaType, chain := range cfg.MetadataProviders {
+		providerChains[media.MediaType(mediaType)] = chain
+	}
+	providers := map[string]metadata.Provider{
+		metadata.ProviderTMDB:        tmdbClient,
+		metadata.ProviderMusicBrainz: mbClient,
+		metadata.ProviderIGDB:        igdbClient,
+		metadata.ProviderUPCItemDB:   upcClient,
+	}
+	var metaCache *metadata.Cache
+	if cfg.MetadataCacheTTL > 0 {
+		metaCache = metadata.NewCache(pool.Pool, cfg.MetadataCacheTTL, cfg.MetadataCacheNegativeTTL)
+		for name, p := range providers {
+			providers[name] = metaCache.Wrap(name, p)
+		}
+	}
+	metaRegistry, err := metadata.BuildRegistry(providers, providerChains)
+	if err != nil {
+		slog.Error("build metadata registry", "error", err)
+		os.Exit(1)
+	}
+	metaSvc := metadata.NewService(metaRegistry, upcClient, mbClient)
+	metaHandler := metadata.NewHandler(metaSvc, metaCache)
 
 	// Media
-	mediaRepo := media.NewRepository(pool.Pool)
+	mediaRepo := media.NewRepository(pool.Pool,
+		media.WithFuzzySearch(cfg.SearchFuzzyThreshold, cfg.SearchFuzzyWeight),
+		media.WithSemanticSearch(media.DefaultSemanticCandidates, cfg.SearchSemanticWeight),
+	)
 	mediaSvc := media.NewService(mediaRepo, metaSvc)
 	mediaHandler := media.NewHandler(mediaSvc)
 
+	// Shared collections
+	collectionRepo := collection.NewRepository(pool.Pool)
+	collectionSvc := collection.NewService(collectionRepo)
+	collectionHandler := collection.NewHandler(collectionSvc)
+
+	// Search history
+	searchLogRepo := searchlog.NewRepository(pool.Pool)
+	searchLogHandler := searchlog.NewHandler(searchLogRepo)
+
 	// AI
 	aiClient := ai.NewClient(cfg.AnthropicAPIKey)
 	aiCache := ai.NewLRUCache(100)
@@ -69,13 +119,41 @@ func main() {
 		slog.Error("init ai service", "error", err)
 		os.Exit(1)
 	}
-	aiHandler := ai.NewHandler(aiSvc, mediaSvc)
+	aiHandler := ai.NewHandler(aiSvc, mediaSvc, searchLogRepo)
+
+	// Release calendar
+	calendarRepo := calendar.NewRepository(pool.Pool)
+	calendarHandler := calendar.NewHandler(calendarRepo, mediaSvc)
+
+	// Goals
+	goalRepo := goal.NewRepository(pool.Pool)
+	goalSvc := goal.NewService(goalRepo)
+	goalHandler := goal.NewHandler(goalSvc)
+
+	// Reviews
+	reviewRepo := review.NewRepository(pool.Pool)
+	reviewSvc := review.NewService(reviewRepo)
+	reviewHandler := review.NewHandler(reviewSvc)
+
+	// Embeddings
+	var embedder embedding.Embedder = embedding.NewHashEmbedder(cfg.EmbeddingDimensions)
+	if cfg.EmbeddingProvider == "openai" {
+		embedder = embedding.NewHTTPEmbedder(cfg.EmbeddingAPIURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel, cfg.EmbeddingDimensions)
+	}
+	embeddingSvc := embedding.NewService(embedding.NewRepository(pool.Pool), embedder)
 
 	// Search
-	searchHandler := search.NewHandler(mediaRepo)
+	searchHandler := search.NewHandler(mediaRepo, metaSvc, embeddingSvc, searchLogRepo, search.HandlerConfig{
+		Highlight: media.HighlightMarkers{
+			Start: cfg.SearchHighlightStart,
+			Stop:  cfg.SearchHighlightStop,
+		},
+		SuggestTimeout:         cfg.SearchSuggestTimeout,
+		ExternalSuggestTimeout: cfg.SearchExternalSuggestTimeout,
+	})
 
 	// Profile
-	profileHandler := profile.NewHandler(pool.Pool, mediaRepo)
+	profileHandler := profile.NewHandler(pool.Pool, mediaRepo, reviewRepo)
 
 	// Activity
 	activityRepo := activity.NewRepository(pool.Pool)
@@ -88,21 +166,62 @@ func main() {
 	r.Route("/api", func(r chi.Router) {
 		r.Post("/auth/register", authHandler.Register)
 		r.Post("/auth/login", authHandler.Login)
-		r.Get("/profile/{username}", profileHandler.GetPublic)
+		r.With(authSvc.OptionalAuth).Get("/profile/{username}", profileHandler.GetPublic)
+		r.With(authSvc.OptionalAuth).Get("/search/discover", searchHandler.Discover)
+		r.Get("/calendar/{token}.ics", calendarHandler.Feed)
 
 		r.Group(func(r chi.Router) {
 			r.Use(authSvc.RequireAuth)
+			r.Use(metadata.CacheBypass)
 
 			r.Get("/auth/me", authHandler.Me)
 
 			r.Get("/media", mediaHandler.List)
 			r.Post("/media", mediaHandler.Create)
+			r.Get("/media/duplicates", mediaHandler.Duplicates)
+			r.Post("/media/duplicates/check", mediaHandler.CheckDuplicates)
+			r.Post("/media/lookup", metaHandler.Lookup)
+			r.Get("/media/pick", mediaHandler.Pick)
+			r.Get("/media/backlog-time", mediaHandler.BacklogTime)
 			r.Get("/media/{id}", mediaHandler.Get)
 			r.Put("/media/{id}", mediaHandler.Update)
 			r.Delete("/media/{id}", mediaHandler.Delete)
 			r.Patch("/media/{id}/status", mediaHandler.UpdateStatus)
+			r.Post("/media/{id}/merge", mediaHandler.Merge)
+			r.Get("/media/{id}/similar", mediaHandler.Similar)
+			r.Put("/media/{id}/collection", mediaHandler.SetCollection)
+			r.Put("/media/{id}/external/{provider}", mediaHandler.LinkExternal)
+			r.Delete("/media/{id}/external/{provider}", mediaHandler.UnlinkExternal)
+			r.Get("/media/{id}/metadata-refreshes", mediaHandler.MetadataRefreshes)
+			r.Get("/media/{id}/review", reviewHandler.Get)
+			r.Put("/media/{id}/review", reviewHandler.Put)
+			r.Delete("/media/{id}/review", reviewHandler.Delete)
+			r.Get("/reviews", reviewHandler.List)
+
+			r.Get("/collections", collectionHandler.List)
+			r.Post("/collections", collectionHandler.Create)
+			r.Get("/collections/{id}", collectionHandler.Get)
+			r.Put("/collections/{id}", collectionHandler.Update)
+			r.Delete("/collections/{id}", collectionHandler.Delete)
+			r.Post("/collections/{id}/members", collectionHandler.AddMember)
+			r.Put("/collections/{id}/members/{userID}", collectionHandler.SetRole)
+			r.Delete("/collections/{id}/members/{userID}", collectionHandler.RemoveMember)
+
+			r.Get("/releases/upcoming", calendarHandler.Upcoming)
+			r.Post("/releases/refresh", calendarHandler.Refresh)
+			r.Post("/calendar/token", calendarHandler.RotateToken)
+			r.Delete("/calendar/token", calendarHandler.RevokeToken)
+
+			r.Get("/goals", goalHandler.List)
+			r.Post("/goals", goalHandler.Create)
+			r.Get("/goals/{id}", goalHandler.Get)
+			r.Put("/goals/{id}", goalHandler.Update)
+			r.Delete("/goals/{id}", goalHandler.Delete)
 
 			r.Get("/search", searchHandler.Search)
+			r.Get("/search/suggest", searchHandler.Suggest)
+			r.Get("/search/history", searchLogHandler.History)
+			r.Delete("/search/history", searchLogHandler.ClearHistory)
 			r.Post("/metadata/search", metaHandler.Search)
 
 			r.Get("/ai/recommendations", aiHandler.Recommendations)
@@ -113,6 +232,11 @@ func main() {
 
 			r.Get("/profile/me", profileHandler.GetMe)
 			r.Put("/profile", profileHandler.Update)
+			r.Post("/profile/{username}/follow", profileHandler.Follow)
+			r.Delete("/profile/{username}/follow", profileHandler.Unfollow)
+			r.Get("/profile/me/followers", profileHandler.Followers)
+			r.Put("/profile/me/followers/{username}", profileHandler.AcceptFollower)
+			r.Delete("/profile/me/followers/{username}", profileHandler.RemoveFollower)
 
 			r.Get("/activity", func(w http.ResponseWriter, r *http.Request) {
 				claims := auth.ClaimsFromCtx(r.Context())
@@ -123,9 +247,30 @@ func main() {
 				}
 				httputil.WriteJSON(w, http.StatusOK, events)
 			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(authSvc.RequireAdmin)
+
+				r.Get("/admin/search/zero-results", searchLogHandler.ZeroResults)
+				r.Delete("/admin/metadata/cache", metaHandler.PurgeCache)
+			})
 		})
 	})
 
+	// Background jobs
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
+	go searchlog.RunRetention(bgCtx, searchLogRepo, cfg.SearchLogRetention, time.Hour)
+	if metaCache != nil {
+		go metadata.RunCacheCleanup(bgCtx, metaCache, time.Hour)
+	}
+	if cfg.MetadataRefreshAge > 0 {
+		// Skip cached responses so refreshes see current provider data.
+		go media.RunMetadataRefresh(metadata.WithCacheBypass(bgCtx), mediaSvc,
+			cfg.MetadataRefreshAge, cfg.MetadataRefreshInterval, cfg.MetadataRefreshBatch)
+	}
+	go embedding.RunRefresh(bgCtx, embeddingSvc, cfg.EmbeddingRefreshInterval)
+
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      r,
@@ -146,6 +291,7 @@ func main() {
 
 	<-quit
 	slog.Info("shutting down server")
+	bgCancel()
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer shutdownCancel()
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/media"
+)
+
+// ErrInvalidFilters is returned when the model's filters fail validation.
+var ErrInvalidFilters = errors.New("invalid filters")
+
+// Plausible release years for any media type.
+const (
+	minReleaseYear = 1850
+	maxReleaseYear = 2100
+)
+
+// rawNLFilters is the model output before normalization. Years are decoded
+// as floats because models sometimes write 2015.0, and every string may come
+// back as "" or "null" instead of a JSON null.
+type rawNLFilters struct {
+	MediaType      *string  `json:"media_type"`
+	Status         *string  `json:"status"`
+	Genre          *string  `json:"genre"`
+	Creator        *string  `json:"creator"`
+	ReleaseYearMin *float64 `json:"release_year_min"`
+	ReleaseYearMax *float64 `json:"release_year_max"`
+	RatingMin      *float64 `json:"rating_min"`
+	TextQuery      *string  `json:"text_query"`
+}
+
+// parseNLFilters decodes and validates the model's filter JSON. Errors wrap
+// ErrInvalidFilters.
+func parseNLFilters(data []byte) (NLFilters, error) {
+	var raw rawNLFilters
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return NLFilters{}, fmt.Errorf("%w: decode: %w", ErrInvalidFilters, err)
+	}
+
+	var f NLFilters
+	if s := cleanString(raw.MediaType); s != nil {
+		mt := media.MediaType(strings.ToLower(*s))
+		f.MediaType = &mt
+	}
+	if s := cleanString(raw.Status); s != nil {
+		st := media.Status(strings.ToLower(*s))
+		f.Status = &st
+	}
+	f.Genre = cleanString(raw.Genre)
+	f.Creator = cleanString(raw.Creator)
+	f.TextQuery = cleanString(raw.TextQuery)
+	if raw.ReleaseYearMin != nil {
+		y := int(math.Round(*raw.ReleaseYearMin))
+		f.ReleaseYearMin = &y
+	}
+	if raw.ReleaseYearMax != nil {
+		y := int(math.Round(*raw.ReleaseYearMax))
+		f.ReleaseYearMax = &y
+	}
+	f.RatingMin = raw.RatingMin
+
+	if err := f.Validate(); err != nil {
+		return NLFilters{}, err
+	}
+	return f, nil
+}
+
+// cleanString trims s and treats empty and "null" values as absent.
+func cleanString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" || strings.EqualFold(v, "null") {
+		return nil
+	}
+	return &v
+}
+
+// Validate checks that every set filter holds a value the collection can
+// match. Errors wrap ErrInvalidFilters.
+func (f NLFilters) Validate() error {
+	if f.MediaType != nil {
+		switch *f.MediaType {
+		case media.MediaTypeMovie, media.MediaTypeMusic, media.MediaTypeGame:
+		default:
+			return fmt.Errorf("%w: unknown media_type %q", ErrInvalidFilters, *f.MediaType)
+		}
+	}
+	if f.Status != nil {
+		switch *f.Status {
+		case media.StatusOwned, media.StatusWishlist, media.StatusCurrentlyUsing, media.StatusCompleted:
+		default:
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidFilters, *f.Status)
+		}
+	}
+	for _, y := range []*int{f.ReleaseYearMin, f.ReleaseYearMax} {
+		if y != nil && (*y < minReleaseYear || *y > maxReleaseYear) {
+			return fmt.Errorf("%w: release year %d out of range", ErrInvalidFilters, *y)
+		}
+	}
+	if f.ReleaseYearMin != nil && f.ReleaseYearMax != nil && *f.ReleaseYearMin > *f.ReleaseYearMax {
+		return fmt.Errorf("%w: release_year_min is after release_year_max", ErrInvalidFilters)
+	}
+	if f.RatingMin != nil && (*f.RatingMin < 0 || *f.RatingMin > 10) {
+		return fmt.Errorf("%w: rating_min must be between 0 and 10", ErrInvalidFilters)
+	}
+	return nil
+}
+
+// ListFilter converts the filters into a media list filter for userID.
+func (f NLFilters) ListFilter(userID uuid.UUID, page, pageSize int) media.ListFilter {
+	return media.ListFilter{
+		UserID:         userID,
+		MediaType:      f.MediaType,
+		Status:         f.Status,
+		Genre:          f.Genre,
+		Creator:        f.Creator,
+		ReleaseYearMin: f.ReleaseYearMin,
+		ReleaseYearMax: f.ReleaseYearMax,
+		RatingMin:      f.RatingMin,
+		TextQuery:      f.TextQuery,
+		Page:           page,
+		PageSize:       pageSize,
+	}
+}
@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+func TestParseNLFilters(t *testing.T) {
+	game := media.MediaTypeGame
+	completed := media.StatusCompleted
+	rpg := "RPG"
+	y1990, y2000 := 1990, 2000
+	eight := 8.0
+
+	tests := []struct {
+		name  string
+		input string
+		want  NLFilters
+	}{
+		{
+			name:  "empty object",
+			input: `{}`,
+		},
+		{
+			name:  "null and empty strings",
+			input: `{"media_type":"null","status":"","genre":" NULL ","creator":null,"text_query":"  "}`,
+		},
+		{
+			name:  "case and whitespace",
+			input: `{"media_type":"Game","status":"COMPLETED","genre":" RPG "}`,
+			want:  NLFilters{MediaType: &game, Status: &completed, Genre: &rpg},
+		},
+		{
+			name:  "float years",
+			input: `{"release_year_min":1990.0,"release_year_max":1999.6,"rating_min":8}`,
+			want:  NLFilters{ReleaseYearMin: &y1990, ReleaseYearMax: &y2000, RatingMin: &eight},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNLFilters([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parseNLFilters error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNLFilters = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNLFiltersRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not json", `the user wants games`},
+		{"wrong type", `{"release_year_min":"nineties"}`},
+		{"unknown media type", `{"media_type":"book"}`},
+		{"unknown status", `{"status":"borrowed"}`},
+		{"year too early", `{"release_year_min":1066}`},
+		{"year too late", `{"release_year_max":3000}`},
+		{"years reversed", `{"release_year_min":2000,"release_year_max":1990}`},
+		{"rating too low", `{"rating_min":-1}`},
+		{"rating too high", `{"rating_min":10.5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseNLFilters([]byte(tt.input)); !errors.Is(err, ErrInvalidFilters) {
+				t.Errorf("parseNLFilters(%s) error = %v, want ErrInvalidFilters", tt.input, err)
+			}
+		})
+	}
+}
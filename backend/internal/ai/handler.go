@@ -2,23 +2,29 @@ package ai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/your-org/ems/internal/auth"
 	"github.com/your-org/ems/internal/httputil"
 	"github.com/your-org/ems/internal/media"
+	"github.com/your-org/ems/internal/searchlog"
 )
 
 // Handler handles HTTP requests for AI endpoints.
 type Handler struct {
-	svc      *Service
-	mediaSvc *media.Service
+	svc       *Service
+	mediaSvc  *media.Service
+	searchLog *searchlog.Repository
 }
 
-// NewHandler creates a new AI Handler.
-func NewHandler(svc *Service, mediaSvc *media.Service) *Handler {
-	return &Handler{svc: svc, mediaSvc: mediaSvc}
+// NewHandler creates a new AI Handler. Natural language searches are
+// recorded in searchLog.
+func NewHandler(svc *Service, mediaSvc *media.Service, searchLog *searchlog.Repository) *Handler {
+	return &Handler{svc: svc, mediaSvc: mediaSvc, searchLog: searchLog}
 }
 
 // Recommendations handles GET /api/ai/recommendations.
@@ -87,20 +93,66 @@ func (h *Handler) Insights(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// NLSearch handles POST /api/ai/nl-search.
+// NLSearch handles POST /api/ai/nl-search. It interprets the query, runs
+// the resulting filters against the user's collection and returns one page
+// of items together with the filters applied. Clients fetching further pages
+// send those filters back, so the query is only interpreted once.
 func (h *Handler) NLSearch(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	start := time.Now()
 	var req struct {
-		Query string `json:"query"`
+		Query    string     `json:"query"`
+		Filters  *NLFilters `json:"filters,omitempty"`
+		Page     int        `json:"page"`
+		PageSize int        `json:"page_size"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	result, err := h.svc.NLSearch(r.Context(), req.Query)
+	if req.Query == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	var result *NLSearchResult
+	if req.Filters != nil {
+		if err := req.Filters.Validate(); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		result = &NLSearchResult{Query: req.Query, Filters: *req.Filters}
+	} else {
+		var err error
+		result, err = h.svc.NLSearch(r.Context(), req.Query)
+		if err != nil {
+			if errors.Is(err, ErrInvalidFilters) {
+				httputil.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	items, total, err := h.mediaSvc.List(r.Context(), result.Filters.ListFilter(claims.UserID, req.Page, req.PageSize))
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	result.Items, result.Total, result.Page = items, total, req.Page
+	// Pages fetched with filters sent back belong to a search already recorded.
+	if req.Page == 1 && req.Filters == nil {
+		if err := h.searchLog.Record(r.Context(), claims.UserID, searchlog.SourceNLSearch, req.Query, total, time.Since(start)); err != nil {
+			slog.Warn("record nl search", "error", err)
+		}
+	}
 	httputil.WriteJSON(w, http.StatusOK, result)
 }
 
@@ -98,8 +98,18 @@ func (s *Service) StreamInsights(ctx context.Context, items []*media.Item, out c
 	return s.client.StreamComplete(ctx, prompt, out)
 }
 
-// NLSearch parses a natural language query into structured filters.
+// NLSearch parses a natural language query into validated filters. The
+// result carries no items; the caller runs the filters against the
+// collection. Filters the model got wrong yield an ErrInvalidFilters error.
+// Interpretations are cached by query, since they do not depend on the user.
 func (s *Service) NLSearch(ctx context.Context, query string) (*NLSearchResult, error) {
+	cacheKey := "nl:" + strings.TrimSpace(query)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if filters, ok := cached.(NLFilters); ok {
+			return &NLSearchResult{Query: query, Filters: filters}, nil
+		}
+	}
+
 	prompt := strings.ReplaceAll(s.prompts["nl_search"], "{{QUERY}}", query)
 
 	result, err := s.client.Complete(ctx, prompt)
@@ -107,12 +117,12 @@ func (s *Service) NLSearch(ctx context.Context, query string) (*NLSearchResult,
 		return nil, fmt.Errorf("nl_search: %w", err)
 	}
 
-	result = extractJSON(result)
-	var filters map[string]any
-	if err := json.Unmarshal([]byte(result), &filters); err != nil {
+	filters, err := parseNLFilters([]byte(extractJSON(result)))
+	if err != nil {
 		return nil, fmt.Errorf("parse nl_search: %w", err)
 	}
 
+	s.cache.Set(cacheKey, filters)
 	return &NLSearchResult{Query: query, Filters: filters}, nil
 }
 
@@ -1,6 +1,8 @@
 // Package ai provides Claude-powered features for the media tracker.
 package ai
 
+import "github.com/your-org/ems/internal/media"
+
 // Recommendation represents a suggested media item.
 type Recommendation struct {
 	Title       string `json:"title"`
@@ -11,10 +13,27 @@ type Recommendation struct {
 	ReleaseYear int    `json:"release_year,omitempty"`
 }
 
-// NLSearchResult is a structured filter parsed from natural language.
+// NLFilters are the collection filters interpreted from a natural language
+// query. Nil fields were not mentioned.
+type NLFilters struct {
+	MediaType      *media.MediaType `json:"media_type"`
+	Status         *media.Status    `json:"status"`
+	Genre          *string          `json:"genre"`
+	Creator        *string          `json:"creator"`
+	ReleaseYearMin *int             `json:"release_year_min"`
+	ReleaseYearMax *int             `json:"release_year_max"`
+	RatingMin      *float64         `json:"rating_min"`
+	TextQuery      *string          `json:"text_query"`
+}
+
+// NLSearchResult is a natural language query, the filters interpreted from
+// it and the matching page of the user's items.
 type NLSearchResult struct {
-	Query   string         `json:"query"`
-	Filters map[string]any `json:"filters"`
+	Query   string        `json:"query"`
+	Filters NLFilters     `json:"filters"`
+	Items   []*media.Item `json:"items"`
+	Total   int           `json:"total"`
+	Page    int           `json:"page"`
 }
 
 // MoodResult holds mood-based discovery suggestions.
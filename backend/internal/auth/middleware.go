@@ -30,6 +30,40 @@ func (s *Service) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// OptionalAuth is HTTP middleware that injects Claims into context when a valid
+// token is present and otherwise lets the request through anonymously.
+func (s *Service) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := extractBearerToken(r); token != "" {
+			if claims, err := s.tokenSvc.Verify(token); err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), claimsKey, claims))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin is HTTP middleware that only lets administrators through. It
+// must run after RequireAuth. Admin status is read from the database on each
+// request so revoking it takes effect without waiting for tokens to expire.
+func (s *Service) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := ClaimsFromCtx(r.Context())
+		if claims == nil {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		var isAdmin bool
+		err := s.db.QueryRow(r.Context(), "SELECT is_admin FROM users WHERE id = $1", claims.UserID).Scan(&isAdmin)
+		if err != nil || !isAdmin {
+			http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // ClaimsFromCtx extracts Claims from context, returns nil if not present.
 func ClaimsFromCtx(ctx context.Context) *Claims {
 	c, _ := ctx.Value(claimsKey).(*Claims)
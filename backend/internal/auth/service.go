@@ -19,6 +19,7 @@ type User struct {
 	Bio         string    `json:"bio"`
 	AvatarURL   string    `json:"avatar_url"`
 	IsPublic    bool      `json:"is_public"`
+	IsAdmin     bool      `json:"is_admin"`
 }
 
 // RegisterRequest holds registration parameters.
@@ -68,10 +69,10 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*User, str
 	err = s.db.QueryRow(ctx, `
 		INSERT INTO users (username, email, password_hash, display_name)
 		VALUES ($1, $2, $3, $1)
-		RETURNING id, username, email, display_name, bio, avatar_url, is_public
+		RETURNING id, username, email, display_name, bio, avatar_url, is_public, is_admin
 	`, req.Username, req.Email, string(hash)).Scan(
 		&user.ID, &user.Username, &user.Email,
-		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic, &user.IsAdmin,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("insert user: %w", err)
@@ -91,11 +92,11 @@ func (s *Service) Login(ctx context.Context, req LoginRequest) (*User, string, e
 	var passwordHash string
 
 	err := s.db.QueryRow(ctx, `
-		SELECT id, username, email, password_hash, display_name, bio, avatar_url, is_public
+		SELECT id, username, email, password_hash, display_name, bio, avatar_url, is_public, is_admin
 		FROM users WHERE email = $1
 	`, req.Email).Scan(
 		&user.ID, &user.Username, &user.Email, &passwordHash,
-		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic, &user.IsAdmin,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -120,11 +121,11 @@ func (s *Service) Login(ctx context.Context, req LoginRequest) (*User, string, e
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	var user User
 	err := s.db.QueryRow(ctx, `
-		SELECT id, username, email, display_name, bio, avatar_url, is_public
+		SELECT id, username, email, display_name, bio, avatar_url, is_public, is_admin
 		FROM users WHERE id = $1
 	`, id).Scan(
 		&user.ID, &user.Username, &user.Email,
-		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPublic, &user.IsAdmin,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
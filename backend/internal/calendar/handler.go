@@ -0,0 +1,101 @@
+package calendar
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/httputil"
+	"github.com/your-org/ems/internal/media"
+)
+
+// feedLimit caps the number of events in a calendar feed.
+const feedLimit = 500
+
+// Handler handles HTTP requests for upcoming releases and the calendar feed.
+type Handler struct {
+	repo     *Repository
+	mediaSvc *media.Service
+}
+
+// NewHandler creates a new calendar Handler.
+func NewHandler(repo *Repository, mediaSvc *media.Service) *Handler {
+	return &Handler{repo: repo, mediaSvc: mediaSvc}
+}
+
+// Upcoming handles GET /api/releases/upcoming.
+func (h *Handler) Upcoming(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	items, err := h.mediaSvc.ListUpcoming(r.Context(), claims.UserID, limit)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, items)
+}
+
+// Refresh handles POST /api/releases/refresh.
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	updated, err := h.mediaSvc.RefreshReleaseDates(r.Context(), claims.UserID)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]int{"updated": updated})
+}
+
+// RotateToken handles POST /api/calendar/token.
+func (h *Handler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	token, err := h.repo.RotateToken(r.Context(), claims.UserID)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusCreated, map[string]string{
+		"token": token,
+		"path":  "/api/calendar/" + token + ".ics",
+	})
+}
+
+// RevokeToken handles DELETE /api/calendar/token.
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	if err := h.repo.RevokeToken(r.Context(), claims.UserID); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Feed handles GET /api/calendar/:token.ics. The token in the path is the
+// only credential, so calendar apps can subscribe without a session.
+func (h *Handler) Feed(w http.ResponseWriter, r *http.Request) {
+	userID, username, err := h.repo.UserForToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+
+	items, err := h.mediaSvc.ListUpcoming(r.Context(), userID, feedLimit)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="upcoming.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if err := writeICS(w, username+"'s upcoming releases", items, time.Now()); err != nil {
+		slog.Error("write calendar feed", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+// maxLineOctets is the RFC 5545 content line limit, excluding the CRLF.
+const maxLineOctets = 75
+
+// writeICS renders upcoming releases as an iCalendar document with one
+// all-day event per item.
+func writeICS(w io.Writer, calName string, items []*media.Item, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//EMS//Upcoming Releases//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(calName),
+		"X-PUBLISHED-TTL:PT12H",
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, item := range items {
+		if item.ReleaseDate == nil {
+			continue
+		}
+		summary := fmt.Sprintf("%s (%s)", item.Title, item.MediaType)
+		description := "Release day for " + item.Title
+		if item.Creator != "" {
+			description += " by " + item.Creator
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+item.ID.String()+"@ems",
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+item.ReleaseDate.Format("20060102"),
+			"DTEND;VALUE=DATE:"+item.ReleaseDate.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+escapeText(summary),
+			"DESCRIPTION:"+escapeText(description),
+			"CATEGORIES:"+escapeText(string(item.MediaType)),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT property value per RFC 5545 section 3.3.11.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// foldLine splits a content line into 75-octet chunks joined by CRLF and a
+// space, without breaking UTF-8 sequences, and terminates it with CRLF.
+func foldLine(line string) string {
+	var sb strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+	return sb.String()
+}
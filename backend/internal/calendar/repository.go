@@ -0,0 +1,74 @@
+// Package calendar publishes upcoming wishlist releases as an iCalendar feed.
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores per-user calendar feed tokens.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new calendar Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RotateToken issues a new feed token for the user, invalidating any previous
+// one. The plaintext token is only available from this call.
+func (r *Repository) RotateToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO calendar_feeds (user_id, token_hash) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = now()
+	`, userID, hashToken(token))
+	if err != nil {
+		return "", fmt.Errorf("store feed token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeToken deletes the user's feed token.
+func (r *Repository) RevokeToken(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, "DELETE FROM calendar_feeds WHERE user_id=$1", userID); err != nil {
+		return fmt.Errorf("revoke feed token: %w", err)
+	}
+	return nil
+}
+
+// UserForToken resolves a feed token to its owner's ID and username.
+func (r *Repository) UserForToken(ctx context.Context, token string) (uuid.UUID, string, error) {
+	var userID uuid.UUID
+	var username string
+	err := r.db.QueryRow(ctx, `
+		SELECT u.id, u.username
+		FROM calendar_feeds f JOIN users u ON u.id = f.user_id
+		WHERE f.token_hash = $1
+	`, hashToken(token)).Scan(&userID, &username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return uuid.Nil, "", fmt.Errorf("feed not found")
+		}
+		return uuid.Nil, "", fmt.Errorf("query feed token: %w", err)
+	}
+	return userID, username, nil
+}
@@ -0,0 +1,193 @@
+package collection
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/httputil"
+)
+
+// Handler handles HTTP requests for collection endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new collection Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// List handles GET /api/collections.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	collections, err := h.svc.List(r.Context(), claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, collections)
+}
+
+// Create handles POST /api/collections.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.svc.Create(r.Context(), claims.UserID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusCreated, c)
+}
+
+// Get handles GET /api/collections/:id.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	detail, err := h.svc.Get(r.Context(), id, claims.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, detail)
+}
+
+// Update handles PUT /api/collections/:id.
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.svc.Rename(r.Context(), id, claims.UserID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, c)
+}
+
+// Delete handles DELETE /api/collections/:id.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, claims.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddMember handles POST /api/collections/:id/members.
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	m, err := h.svc.AddMember(r.Context(), id, claims.UserID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusCreated, m)
+}
+
+// SetRole handles PUT /api/collections/:id/members/:userID.
+func (h *Handler) SetRole(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	memberID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	var req RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.svc.SetRole(r.Context(), id, claims.UserID, memberID, req.Role); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember handles DELETE /api/collections/:id/members/:userID.
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	memberID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), id, claims.UserID, memberID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps a service error to its HTTP status.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrForbidden):
+		httputil.WriteError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrNotFound):
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+	default:
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
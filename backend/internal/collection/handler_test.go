@@ -0,0 +1,30 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: a collection must keep at least one owner", ErrInvalidRequest), http.StatusBadRequest},
+		{ErrForbidden, http.StatusForbidden},
+		{fmt.Errorf("collection %w", ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("member %w", ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("count owners: %w", errors.New("connection reset")), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeError(rec, tt.err)
+		if rec.Code != tt.want {
+			t.Errorf("writeError(%q) status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package collection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles database operations for collections and their members.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new collection Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const collectionColumns = `c.id, c.name, c.created_by, cm.role,
+	(SELECT COUNT(*) FROM collection_members x WHERE x.collection_id = c.id),
+	(SELECT COUNT(*) FROM media_items m WHERE m.collection_id = c.id),
+	c.created_at, c.updated_at`
+
+func scanCollection(row pgx.Row) (*Collection, error) {
+	var c Collection
+	err := row.Scan(
+		&c.ID, &c.Name, &c.CreatedBy, &c.Role,
+		&c.MemberCount, &c.ItemCount, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Create inserts a collection with userID as its first owner.
+func (r *Repository) Create(ctx context.Context, userID uuid.UUID, name string) (*Collection, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO collections (name, created_by) VALUES ($1, $2) RETURNING id", name, userID,
+	).Scan(&id); err != nil {
+		return nil, fmt.Errorf("insert collection: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO collection_members (collection_id, user_id, role) VALUES ($1, $2, 'owner')", id, userID,
+	); err != nil {
+		return nil, fmt.Errorf("insert owner: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit collection: %w", err)
+	}
+	return r.Get(ctx, id, userID)
+}
+
+// Get fetches a collection the user is a member of.
+func (r *Repository) Get(ctx context.Context, id, userID uuid.UUID) (*Collection, error) {
+	row := r.db.QueryRow(ctx, `
+		SELECT `+collectionColumns+`
+		FROM collections c JOIN collection_members cm ON cm.collection_id = c.id
+		WHERE c.id = $1 AND cm.user_id = $2`,
+		id, userID,
+	)
+	c, err := scanCollection(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("collection %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("query collection: %w", err)
+	}
+	return c, nil
+}
+
+// ListForUser returns every collection the user belongs to.
+func (r *Repository) ListForUser(ctx context.Context, userID uuid.UUID) ([]*Collection, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+collectionColumns+`
+		FROM collections c JOIN collection_members cm ON cm.collection_id = c.id
+		WHERE cm.user_id = $1
+		ORDER BY c.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := make([]*Collection, 0)
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// Rename changes a collection's name.
+func (r *Repository) Rename(ctx context.Context, id uuid.UUID, name string) error {
+	result, err := r.db.Exec(ctx, "UPDATE collections SET name=$1 WHERE id=$2", name, id)
+	if err != nil {
+		return fmt.Errorf("rename collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("collection %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Delete removes a collection. Its items stay with the members who created them.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM collections WHERE id=$1", id)
+	if err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("collection %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Members lists the members of a collection, owners first.
+func (r *Repository) Members(ctx context.Context, id uuid.UUID) ([]*Member, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.username, u.display_name, cm.role, cm.added_at
+		FROM collection_members cm JOIN users u ON u.id = cm.user_id
+		WHERE cm.collection_id = $1
+		ORDER BY cm.role, u.username`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*Member, 0)
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.UserID, &m.Username, &m.DisplayName, &m.Role, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember adds the user with the given username, or changes their role if
+// they are already a member. A collection must keep at least one owner.
+func (r *Repository) AddMember(ctx context.Context, id uuid.UUID, username string, role Role) (*Member, error) {
+	var m Member
+	err := r.changeMembers(ctx, id, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			WITH added AS (
+				INSERT INTO collection_members (collection_id, user_id, role)
+				SELECT $1, u.id, $3 FROM users u WHERE u.username = $2
+				ON CONFLICT (collection_id, user_id) DO UPDATE SET role = EXCLUDED.role
+				RETURNING user_id, role, added_at
+			)
+			SELECT u.id, u.username, u.display_name, a.role, a.added_at
+			FROM added a JOIN users u ON u.id = a.user_id`,
+			id, username, role,
+		).Scan(&m.UserID, &m.Username, &m.DisplayName, &m.Role, &m.AddedAt)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("user %w", ErrNotFound)
+			}
+			return fmt.Errorf("add member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetRole changes a member's role. A collection must keep at least one owner.
+func (r *Repository) SetRole(ctx context.Context, id, memberID uuid.UUID, role Role) error {
+	return r.changeMember(ctx, id,
+		"UPDATE collection_members SET role=$3 WHERE collection_id=$1 AND user_id=$2",
+		memberID, role,
+	)
+}
+
+// RemoveMember removes a member. A collection must keep at least one owner.
+func (r *Repository) RemoveMember(ctx context.Context, id, memberID uuid.UUID) error {
+	return r.changeMember(ctx, id,
+		"DELETE FROM collection_members WHERE collection_id=$1 AND user_id=$2",
+		memberID,
+	)
+}
+
+// changeMember runs a statement against one membership row through
+// changeMembers.
+func (r *Repository) changeMember(ctx context.Context, id uuid.UUID, stmt string, args ...any) error {
+	return r.changeMembers(ctx, id, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, stmt, append([]any{id}, args...)...)
+		if err != nil {
+			return fmt.Errorf("update member: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("member %w", ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// changeMembers runs change in a transaction and rolls it back if it would
+// leave the collection without an owner.
+func (r *Repository) changeMembers(ctx context.Context, id uuid.UUID, change func(pgx.Tx) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Lock the collection so concurrent changes cannot both remove the last owner.
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM collections WHERE id=$1 FOR UPDATE", id); err != nil {
+		return fmt.Errorf("lock collection: %w", err)
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	var owners int
+	if err := tx.QueryRow(ctx,
+		"SELECT COUNT(*) FROM collection_members WHERE collection_id=$1 AND role='owner'", id,
+	).Scan(&owners); err != nil {
+		return fmt.Errorf("count owners: %w", err)
+	}
+	if owners == 0 {
+		return fmt.Errorf("%w: a collection must keep at least one owner", ErrInvalidRequest)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit member change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for collections the user does not belong to and
+	// for unknown members and users.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden is returned when the user's role does not allow a change.
+	ErrForbidden = errors.New("only collection owners can do this")
+	// ErrInvalidRequest wraps validation errors, including changes that would
+	// leave a collection without an owner.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// Service enforces collection roles on top of the Repository.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new collection Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create adds a collection owned by userID.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Collection, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	return s.repo.Create(ctx, userID, name)
+}
+
+// List returns the collections the user belongs to.
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Collection, error) {
+	return s.repo.ListForUser(ctx, userID)
+}
+
+// Get returns a collection and its members.
+func (s *Service) Get(ctx context.Context, id, userID uuid.UUID) (*Detail, error) {
+	c, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.Members(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Detail{Collection: c, Members: members}, nil
+}
+
+// Rename changes the collection's name. Only owners may rename.
+func (s *Service) Rename(ctx context.Context, id, userID uuid.UUID, req UpdateRequest) (*Collection, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidRequest)
+	}
+	if _, err := s.requireOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rename(ctx, id, name); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id, userID)
+}
+
+// Delete removes the collection. Only owners may delete.
+func (s *Service) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	if _, err := s.requireOwner(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// AddMember adds a user by username. Only owners may manage members.
+func (s *Service) AddMember(ctx context.Context, id, userID uuid.UUID, req AddMemberRequest) (*Member, error) {
+	if req.Username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidRequest)
+	}
+	if req.Role == "" {
+		req.Role = RoleViewer
+	}
+	if !req.Role.Valid() {
+		return nil, fmt.Errorf("%w: role must be owner, editor or viewer", ErrInvalidRequest)
+	}
+	if _, err := s.requireOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.AddMember(ctx, id, req.Username, req.Role)
+}
+
+// SetRole changes a member's role. Only owners may manage members.
+func (s *Service) SetRole(ctx context.Context, id, userID, memberID uuid.UUID, role Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("%w: role must be owner, editor or viewer", ErrInvalidRequest)
+	}
+	if _, err := s.requireOwner(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.repo.SetRole(ctx, id, memberID, role)
+}
+
+// RemoveMember removes a member. Owners may remove anyone; other members may
+// only leave.
+func (s *Service) RemoveMember(ctx context.Context, id, userID, memberID uuid.UUID) error {
+	if memberID != userID {
+		if _, err := s.requireOwner(ctx, id, userID); err != nil {
+			return err
+		}
+	} else if _, err := s.repo.Get(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(ctx, id, memberID)
+}
+
+func (s *Service) requireOwner(ctx context.Context, id, userID uuid.UUID) (*Collection, error) {
+	c, err := s.repo.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Role != RoleOwner {
+		return nil, ErrForbidden
+	}
+	return c, nil
+}
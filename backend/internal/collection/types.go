@@ -0,0 +1,74 @@
+// Package collection provides shared collections whose media items are owned
+// and edited by several users.
+package collection
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a member's permission level within a collection.
+type Role string
+
+const (
+	// RoleOwner can edit items and manage the collection and its members.
+	RoleOwner Role = "owner"
+	// RoleEditor can add, edit and remove items.
+	RoleEditor Role = "editor"
+	// RoleViewer can see items and keep a personal status and rating.
+	RoleViewer Role = "viewer"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r == RoleOwner || r == RoleEditor || r == RoleViewer
+}
+
+// Collection is a shared set of media items, as seen by one member.
+type Collection struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	Role        Role      `json:"role"`
+	MemberCount int       `json:"member_count"`
+	ItemCount   int       `json:"item_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Member is a user belonging to a collection.
+type Member struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Role        Role      `json:"role"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// Detail is a collection together with its members.
+type Detail struct {
+	*Collection
+	Members []*Member `json:"members"`
+}
+
+// CreateRequest is the payload for creating a collection.
+type CreateRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateRequest is the payload for renaming a collection.
+type UpdateRequest struct {
+	Name string `json:"name"`
+}
+
+// AddMemberRequest is the payload for adding a user to a collection.
+type AddMemberRequest struct {
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+}
+
+// RoleRequest is the payload for changing a member's role.
+type RoleRequest struct {
+	Role Role `json:"role"`
+}
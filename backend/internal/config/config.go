@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,6 +31,58 @@ type Config struct {
 	TMDBAPIKey       string
 	IGDBClientID     string
 	IGDBClientSecret string
+	UPCItemDBAPIKey  string
+
+	// MetadataProviders maps each media type to the metadata providers tried
+	// for it, in order.
+	MetadataProviders map[string][]string
+
+	MetadataCacheTTL         time.Duration
+	MetadataCacheNegativeTTL time.Duration
+
+	// Requests per second allowed to each provider; 0 means unlimited.
+	TMDBRateLimit        float64
+	MusicBrainzRateLimit float64
+	IGDBRateLimit        float64
+	UPCItemDBRateLimit   float64
+
+	TMDBTimeout        time.Duration
+	MusicBrainzTimeout time.Duration
+	IGDBTimeout        time.Duration
+	UPCItemDBTimeout   time.Duration
+
+	MetadataMaxRetries       int
+	MetadataBreakerThreshold int
+	MetadataBreakerCooldown  time.Duration
+
+	// Items linked to a provider ID are re-fetched once their metadata is
+	// older than MetadataRefreshAge (0 disables refreshing), checking every
+	// MetadataRefreshInterval for up to MetadataRefreshBatch items.
+	MetadataRefreshAge      time.Duration
+	MetadataRefreshInterval time.Duration
+	MetadataRefreshBatch    int
+
+	// Search
+	SearchHighlightStart string
+	SearchHighlightStop  string
+	SearchFuzzyThreshold float64
+	SearchFuzzyWeight    float64
+
+	SearchSuggestTimeout         time.Duration
+	SearchExternalSuggestTimeout time.Duration
+	SearchSemanticWeight         float64
+	SearchLogRetention           time.Duration
+
+	// Embeddings
+	EmbeddingProvider   string
+	EmbeddingAPIURL     string
+	EmbeddingAPIKey     string
+	EmbeddingModel      string
+	EmbeddingDimensions int
+
+	// Items whose vectors are missing or out of date are embedded every
+	// EmbeddingRefreshInterval.
+	EmbeddingRefreshInterval time.Duration
 }
 
 // Option is a functional option for Config.
@@ -55,6 +108,44 @@ func Load(opts ...Option) (*Config, error) {
 		JWTExpiration:   7 * 24 * time.Hour,
 		BcryptCost:      12,
 		FrontendURL:     getEnvOrDefault("FRONTEND_URL", "http://localhost:3000"),
+
+		SearchHighlightStart: getEnvOrDefault("SEARCH_HIGHLIGHT_START", "<mark>"),
+		SearchHighlightStop:  getEnvOrDefault("SEARCH_HIGHLIGHT_STOP", "</mark>"),
+		SearchFuzzyThreshold: 0.5,
+		SearchFuzzyWeight:    0.4,
+
+		SearchSuggestTimeout:         150 * time.Millisecond,
+		SearchExternalSuggestTimeout: 1500 * time.Millisecond,
+		SearchSemanticWeight:         0.5,
+		SearchLogRetention:           90 * 24 * time.Hour,
+
+		EmbeddingProvider:   getEnvOrDefault("EMBEDDING_PROVIDER", "hash"),
+		EmbeddingAPIURL:     getEnvOrDefault("EMBEDDING_API_URL", "https://api.openai.com/v1"),
+		EmbeddingModel:      getEnvOrDefault("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingDimensions: 256,
+
+		MetadataProviders: map[string][]string{
+			"movie": splitList(getEnvOrDefault("METADATA_PROVIDERS_MOVIE", "tmdb")),
+			"music": splitList(getEnvOrDefault("METADATA_PROVIDERS_MUSIC", "musicbrainz")),
+			"game":  splitList(getEnvOrDefault("METADATA_PROVIDERS_GAME", "igdb")),
+		},
+		MetadataCacheTTL:         7 * 24 * time.Hour,
+		MetadataCacheNegativeTTL: time.Hour,
+		TMDBRateLimit:            20,
+		MusicBrainzRateLimit:     1,
+		IGDBRateLimit:            4,
+		UPCItemDBRateLimit:       0.1,
+		TMDBTimeout:              10 * time.Second,
+		MusicBrainzTimeout:       15 * time.Second,
+		IGDBTimeout:              10 * time.Second,
+		UPCItemDBTimeout:         10 * time.Second,
+		MetadataMaxRetries:       2,
+		MetadataBreakerThreshold: 5,
+		MetadataBreakerCooldown:  30 * time.Second,
+		MetadataRefreshAge:       30 * 24 * time.Hour,
+		MetadataRefreshInterval:  time.Hour,
+		MetadataRefreshBatch:     100,
+		EmbeddingRefreshInterval: time.Minute,
 	}
 
 	cfg.DatabaseURL = os.Getenv("DATABASE_URL")
@@ -63,6 +154,8 @@ func Load(opts ...Option) (*Config, error) {
 	cfg.TMDBAPIKey = os.Getenv("TMDB_API_KEY")
 	cfg.IGDBClientID = os.Getenv("IGDB_CLIENT_ID")
 	cfg.IGDBClientSecret = os.Getenv("IGDB_CLIENT_SECRET")
+	cfg.UPCItemDBAPIKey = os.Getenv("UPCITEMDB_API_KEY")
+	cfg.EmbeddingAPIKey = os.Getenv("EMBEDDING_API_KEY")
 
 	if costStr := os.Getenv("BCRYPT_COST"); costStr != "" {
 		cost, err := strconv.Atoi(costStr)
@@ -72,6 +165,175 @@ func Load(opts ...Option) (*Config, error) {
 		cfg.BcryptCost = cost
 	}
 
+	if s := os.Getenv("SEARCH_FUZZY_THRESHOLD"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_FUZZY_THRESHOLD: %w", err)
+		}
+		cfg.SearchFuzzyThreshold = v
+	}
+	if s := os.Getenv("SEARCH_FUZZY_WEIGHT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_FUZZY_WEIGHT: %w", err)
+		}
+		cfg.SearchFuzzyWeight = v
+	}
+	if s := os.Getenv("SEARCH_SUGGEST_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_SUGGEST_TIMEOUT: %w", err)
+		}
+		cfg.SearchSuggestTimeout = d
+	}
+	if s := os.Getenv("SEARCH_EXTERNAL_SUGGEST_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_EXTERNAL_SUGGEST_TIMEOUT: %w", err)
+		}
+		cfg.SearchExternalSuggestTimeout = d
+	}
+	if s := os.Getenv("SEARCH_SEMANTIC_WEIGHT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_SEMANTIC_WEIGHT: %w", err)
+		}
+		cfg.SearchSemanticWeight = v
+	}
+	if s := os.Getenv("SEARCH_LOG_RETENTION"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse SEARCH_LOG_RETENTION: %w", err)
+		}
+		cfg.SearchLogRetention = d
+	}
+	if s := os.Getenv("METADATA_CACHE_TTL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_CACHE_TTL: %w", err)
+		}
+		cfg.MetadataCacheTTL = d
+	}
+	if s := os.Getenv("METADATA_CACHE_NEGATIVE_TTL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_CACHE_NEGATIVE_TTL: %w", err)
+		}
+		cfg.MetadataCacheNegativeTTL = d
+	}
+	if s := os.Getenv("TMDB_RATE_LIMIT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse TMDB_RATE_LIMIT: %w", err)
+		}
+		cfg.TMDBRateLimit = v
+	}
+	if s := os.Getenv("MUSICBRAINZ_RATE_LIMIT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse MUSICBRAINZ_RATE_LIMIT: %w", err)
+		}
+		cfg.MusicBrainzRateLimit = v
+	}
+	if s := os.Getenv("IGDB_RATE_LIMIT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse IGDB_RATE_LIMIT: %w", err)
+		}
+		cfg.IGDBRateLimit = v
+	}
+	if s := os.Getenv("UPCITEMDB_RATE_LIMIT"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse UPCITEMDB_RATE_LIMIT: %w", err)
+		}
+		cfg.UPCItemDBRateLimit = v
+	}
+	if s := os.Getenv("TMDB_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse TMDB_TIMEOUT: %w", err)
+		}
+		cfg.TMDBTimeout = d
+	}
+	if s := os.Getenv("MUSICBRAINZ_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse MUSICBRAINZ_TIMEOUT: %w", err)
+		}
+		cfg.MusicBrainzTimeout = d
+	}
+	if s := os.Getenv("IGDB_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse IGDB_TIMEOUT: %w", err)
+		}
+		cfg.IGDBTimeout = d
+	}
+	if s := os.Getenv("UPCITEMDB_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse UPCITEMDB_TIMEOUT: %w", err)
+		}
+		cfg.UPCItemDBTimeout = d
+	}
+	if s := os.Getenv("METADATA_MAX_RETRIES"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_MAX_RETRIES: %w", err)
+		}
+		cfg.MetadataMaxRetries = n
+	}
+	if s := os.Getenv("METADATA_BREAKER_THRESHOLD"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_BREAKER_THRESHOLD: %w", err)
+		}
+		cfg.MetadataBreakerThreshold = n
+	}
+	if s := os.Getenv("METADATA_BREAKER_COOLDOWN"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_BREAKER_COOLDOWN: %w", err)
+		}
+		cfg.MetadataBreakerCooldown = d
+	}
+	if s := os.Getenv("METADATA_REFRESH_AGE"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_REFRESH_AGE: %w", err)
+		}
+		cfg.MetadataRefreshAge = d
+	}
+	if s := os.Getenv("METADATA_REFRESH_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_REFRESH_INTERVAL: %w", err)
+		}
+		cfg.MetadataRefreshInterval = d
+	}
+	if s := os.Getenv("METADATA_REFRESH_BATCH"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse METADATA_REFRESH_BATCH: %w", err)
+		}
+		cfg.MetadataRefreshBatch = n
+	}
+	if s := os.Getenv("EMBEDDING_DIMENSIONS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse EMBEDDING_DIMENSIONS: %w", err)
+		}
+		cfg.EmbeddingDimensions = n
+	}
+	if s := os.Getenv("EMBEDDING_REFRESH_INTERVAL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse EMBEDDING_REFRESH_INTERVAL: %w", err)
+		}
+		cfg.EmbeddingRefreshInterval = d
+	}
+
 	for _, opt := range opts {
 		opt(cfg)
 	}
@@ -90,6 +352,59 @@ func (c *Config) validate() error {
 	if c.JWTSecret == "" {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
+	if c.SearchFuzzyThreshold < 0 || c.SearchFuzzyThreshold > 1 {
+		return fmt.Errorf("SEARCH_FUZZY_THRESHOLD must be between 0 and 1")
+	}
+	if c.SearchFuzzyWeight < 0 || c.SearchFuzzyWeight > 1 {
+		return fmt.Errorf("SEARCH_FUZZY_WEIGHT must be between 0 and 1")
+	}
+	if c.SearchSuggestTimeout <= 0 || c.SearchExternalSuggestTimeout <= 0 {
+		return fmt.Errorf("search suggestion timeouts must be positive")
+	}
+	if c.SearchSemanticWeight < 0 || c.SearchSemanticWeight > 1 {
+		return fmt.Errorf("SEARCH_SEMANTIC_WEIGHT must be between 0 and 1")
+	}
+	if c.SearchLogRetention <= 0 {
+		return fmt.Errorf("SEARCH_LOG_RETENTION must be positive")
+	}
+	switch c.EmbeddingProvider {
+	case "hash":
+		if c.EmbeddingDimensions <= 0 {
+			return fmt.Errorf("EMBEDDING_DIMENSIONS must be positive for the hash provider")
+		}
+	case "openai":
+	default:
+		return fmt.Errorf("EMBEDDING_PROVIDER must be hash or openai")
+	}
+	if c.EmbeddingDimensions < 0 {
+		return fmt.Errorf("EMBEDDING_DIMENSIONS must not be negative")
+	}
+	if c.EmbeddingRefreshInterval <= 0 {
+		return fmt.Errorf("EMBEDDING_REFRESH_INTERVAL must be positive")
+	}
+	if c.MetadataCacheTTL < 0 || c.MetadataCacheNegativeTTL < 0 {
+		return fmt.Errorf("metadata cache TTLs must not be negative")
+	}
+	if c.TMDBRateLimit < 0 || c.MusicBrainzRateLimit < 0 || c.IGDBRateLimit < 0 || c.UPCItemDBRateLimit < 0 {
+		return fmt.Errorf("provider rate limits must not be negative")
+	}
+	if c.TMDBTimeout <= 0 || c.MusicBrainzTimeout <= 0 || c.IGDBTimeout <= 0 || c.UPCItemDBTimeout <= 0 {
+		return fmt.Errorf("provider timeouts must be positive")
+	}
+	if c.MetadataMaxRetries < 0 || c.MetadataBreakerThreshold < 0 || c.MetadataBreakerCooldown < 0 {
+		return fmt.Errorf("metadata retry and circuit breaker settings must not be negative")
+	}
+	if c.MetadataRefreshAge < 0 {
+		return fmt.Errorf("METADATA_REFRESH_AGE must not be negative")
+	}
+	if c.MetadataRefreshAge > 0 && (c.MetadataRefreshInterval <= 0 || c.MetadataRefreshBatch <= 0) {
+		return fmt.Errorf("METADATA_REFRESH_INTERVAL and METADATA_REFRESH_BATCH must be positive")
+	}
+	for mediaType, chain := range c.MetadataProviders {
+		if len(chain) == 0 {
+			return fmt.Errorf("METADATA_PROVIDERS_%s must name at least one provider", strings.ToUpper(mediaType))
+		}
+	}
 	return nil
 }
 
@@ -99,3 +414,14 @@ func getEnvOrDefault(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    body TEXT NOT NULL DEFAULT '',
+    body_html TEXT NOT NULL DEFAULT '',
+    search_text TEXT NOT NULL DEFAULT '',
+    has_spoilers BOOLEAN NOT NULL DEFAULT false,
+    published BOOLEAN NOT NULL DEFAULT false,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, media_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_media_item_id ON reviews (media_item_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_user_published ON reviews (user_id, published_at DESC) WHERE published;
+
+CREATE TRIGGER reviews_updated_at
+    BEFORE UPDATE ON reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Published review text (spoiler sections excluded) joins the item's search_vector
+-- Weighted: title A, creator B, genre C, notes D, reviews D
+CREATE OR REPLACE FUNCTION media_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.creator, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(array_to_string(NEW.genre, ' '), '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.notes, '')), 'D') ||
+        setweight(to_tsvector('english', coalesce((
+            SELECT string_agg(r.search_text, ' ')
+            FROM reviews r
+            WHERE r.media_item_id = NEW.id AND r.published
+        ), '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Touch the parent item so its search_vector is recomputed when a review changes
+CREATE OR REPLACE FUNCTION reviews_refresh_search_vector() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE media_items SET search_vector = NULL WHERE id = OLD.media_item_id;
+    END IF;
+    IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.media_item_id <> OLD.media_item_id) THEN
+        UPDATE media_items SET search_vector = NULL WHERE id = NEW.media_item_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_search_vector_trigger
+    AFTER INSERT OR UPDATE OR DELETE ON reviews
+    FOR EACH ROW EXECUTE FUNCTION reviews_refresh_search_vector();
@@ -0,0 +1,12 @@
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS release_date DATE;
+
+CREATE INDEX IF NOT EXISTS idx_media_user_wishlist_release
+    ON media_items (user_id, release_date)
+    WHERE status = 'wishlist' AND release_date IS NOT NULL;
+
+-- Per-user secret for the subscribable iCalendar feed; only a hash is stored
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
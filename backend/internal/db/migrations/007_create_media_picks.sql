@@ -0,0 +1,9 @@
+-- Items suggested by the random picker, so recent suggestions can be skipped
+CREATE TABLE IF NOT EXISTS media_picks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    picked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_picks_user_item ON media_picks (user_id, media_item_id, picked_at DESC);
@@ -0,0 +1,5 @@
+-- Typed time estimates captured during metadata enrichment
+ALTER TABLE media_items
+    ADD COLUMN IF NOT EXISTS runtime_minutes INT CHECK (runtime_minutes >= 0),
+    ADD COLUMN IF NOT EXISTS duration_minutes INT CHECK (duration_minutes >= 0),
+    ADD COLUMN IF NOT EXISTS time_to_beat_minutes INT CHECK (time_to_beat_minutes >= 0);
@@ -0,0 +1,56 @@
+-- Every status an item enters, so progress can be computed from transitions
+CREATE TABLE IF NOT EXISTS media_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    from_status media_status,
+    to_status media_status NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_user ON media_status_history (user_id, to_status, changed_at);
+CREATE INDEX IF NOT EXISTS idx_status_history_item ON media_status_history (media_item_id);
+
+CREATE OR REPLACE FUNCTION media_status_history_record() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO media_status_history (user_id, media_item_id, from_status, to_status)
+        VALUES (NEW.user_id, NEW.id, NULL, NEW.status);
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO media_status_history (user_id, media_item_id, from_status, to_status)
+        VALUES (NEW.user_id, NEW.id, OLD.status, NEW.status);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER media_status_history_trigger
+    AFTER INSERT OR UPDATE OF status ON media_items
+    FOR EACH ROW EXECUTE FUNCTION media_status_history_record();
+
+-- Backfill the current status of existing items, dated by their last update
+INSERT INTO media_status_history (user_id, media_item_id, from_status, to_status, changed_at)
+SELECT user_id, id, NULL, status, updated_at FROM media_items;
+
+CREATE TYPE goal_metric AS ENUM ('completed', 'started', 'added');
+
+CREATE TABLE IF NOT EXISTS goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    metric goal_metric NOT NULL DEFAULT 'completed',
+    target_count INT NOT NULL CHECK (target_count > 0),
+    media_type media_type,
+    genre TEXT,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user ON goals (user_id, period_end DESC);
+
+CREATE TRIGGER goals_updated_at
+    BEFORE UPDATE ON goals
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
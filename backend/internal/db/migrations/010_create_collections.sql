@@ -0,0 +1,61 @@
+-- Shared collections let several users own and edit the same media items
+CREATE TYPE collection_role AS ENUM ('owner', 'editor', 'viewer');
+
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER collections_updated_at
+    BEFORE UPDATE ON collections
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS collection_members (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role collection_role NOT NULL DEFAULT 'viewer',
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_members_user ON collection_members (user_id);
+
+-- Items stay owned by their creator; a collection shares them with its members
+ALTER TABLE media_items ADD COLUMN collection_id UUID REFERENCES collections(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_media_collection ON media_items (collection_id);
+
+-- Personal status and rating of members other than the item's creator
+CREATE TABLE IF NOT EXISTS media_item_user_state (
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status media_status NOT NULL DEFAULT 'owned',
+    rating NUMERIC(3,1) CHECK (rating >= 0 AND rating <= 10),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (media_item_id, user_id)
+);
+
+CREATE TRIGGER media_item_user_state_updated_at
+    BEFORE UPDATE ON media_item_user_state
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Record personal status changes in the same history goals are computed from
+CREATE OR REPLACE FUNCTION media_user_state_history_record() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO media_status_history (user_id, media_item_id, from_status, to_status)
+        VALUES (NEW.user_id, NEW.media_item_id, NULL, NEW.status);
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO media_status_history (user_id, media_item_id, from_status, to_status)
+        VALUES (NEW.user_id, NEW.media_item_id, OLD.status, NEW.status);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER media_user_state_history_trigger
+    AFTER INSERT OR UPDATE OF status ON media_item_user_state
+    FOR EACH ROW EXECUTE FUNCTION media_user_state_history_record();
@@ -0,0 +1,20 @@
+-- Per-item visibility on public profiles, with a per-user default
+CREATE TYPE item_visibility AS ENUM ('public', 'followers', 'private');
+
+ALTER TABLE users ADD COLUMN default_visibility item_visibility NOT NULL DEFAULT 'public';
+
+ALTER TABLE media_items ADD COLUMN visibility item_visibility NOT NULL DEFAULT 'public';
+-- Notes are private unless explicitly shared; this also hides existing notes
+ALTER TABLE media_items ADD COLUMN notes_private BOOLEAN NOT NULL DEFAULT true;
+
+CREATE INDEX IF NOT EXISTS idx_media_user_visibility ON media_items (user_id, visibility);
+
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_followee ON follows (followee_id);
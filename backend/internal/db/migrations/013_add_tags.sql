@@ -0,0 +1,4 @@
+-- Free-form user tags alongside provider genres
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_media_tags ON media_items USING GIN (tags);
@@ -0,0 +1,17 @@
+-- Requires the pgvector extension (e.g. the pgvector/pgvector:pg16 image)
+CREATE EXTENSION IF NOT EXISTS vector;
+
+-- One vector per item and embedding model. The column is left without a
+-- fixed dimension so models of different sizes can coexist; queries always
+-- compare vectors of a single model. Collections are scanned per user, so
+-- no approximate index is needed.
+CREATE TABLE IF NOT EXISTS media_embeddings (
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    model TEXT NOT NULL,
+    embedding vector NOT NULL,
+    content_hash TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (media_item_id, model)
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_embeddings_model ON media_embeddings (model);
@@ -0,0 +1,17 @@
+-- Administrators can view aggregate search analytics
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+
+-- Search queries per user, kept for a limited retention period
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    query TEXT NOT NULL,
+    result_count INT NOT NULL,
+    latency_ms INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_user ON search_queries (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_search_queries_created ON search_queries (created_at);
+CREATE INDEX IF NOT EXISTS idx_search_queries_zero ON search_queries (created_at) WHERE result_count = 0;
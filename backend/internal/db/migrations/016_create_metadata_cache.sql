@@ -0,0 +1,12 @@
+-- Cached metadata provider responses; a NULL response caches "no match"
+CREATE TABLE IF NOT EXISTS metadata_cache (
+    provider TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    query_key TEXT NOT NULL,
+    response JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (provider, operation, query_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_cache_expires ON metadata_cache (expires_at);
@@ -0,0 +1,22 @@
+-- When an item's metadata was last fetched from its provider; existing items
+-- were enriched, if at all, when they were created
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS metadata_refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE media_items DISABLE TRIGGER media_items_updated_at;
+UPDATE media_items SET metadata_refreshed_at = created_at;
+ALTER TABLE media_items ENABLE TRIGGER media_items_updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_media_metadata_refreshed
+    ON media_items (metadata_refreshed_at)
+    WHERE tmdb_id IS NOT NULL OR musicbrainz_id IS NOT NULL OR igdb_id IS NOT NULL;
+
+-- Field changes made by each metadata refresh, as {"field": {"old": ..., "new": ...}}
+CREATE TABLE IF NOT EXISTS metadata_refreshes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    media_item_id UUID NOT NULL REFERENCES media_items(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    changes JSONB NOT NULL,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_metadata_refreshes_item ON metadata_refreshes (media_item_id, refreshed_at DESC);
@@ -0,0 +1,23 @@
+-- Notes leave the stored search_vector: private notes must not be searchable
+-- by other collection members. Queries add the notes the viewer may read.
+-- Weighted: title A, creator B, genre C, overview D, reviews D
+CREATE OR REPLACE FUNCTION media_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.creator, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(array_to_string(NEW.genre, ' '), '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.metadata->>'overview', '')), 'D') ||
+        setweight(to_tsvector('english', coalesce((
+            SELECT string_agg(r.search_text, ' ')
+            FROM reviews r
+            WHERE r.media_item_id = NEW.id AND r.published
+        ), '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Recompute existing vectors without bumping updated_at
+ALTER TABLE media_items DISABLE TRIGGER media_items_updated_at;
+UPDATE media_items SET search_vector = NULL WHERE notes <> '';
+ALTER TABLE media_items ENABLE TRIGGER media_items_updated_at;
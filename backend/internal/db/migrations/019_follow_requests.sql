@@ -0,0 +1,7 @@
+-- Following a user is a request until they accept it; only accepted
+-- followers see followers-only items. Existing follows were never approved,
+-- so they start out as pending requests.
+ALTER TABLE follows ADD COLUMN IF NOT EXISTS accepted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE follows ADD COLUMN IF NOT EXISTS accepted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_follows_followee_pending ON follows (followee_id, created_at) WHERE NOT accepted;
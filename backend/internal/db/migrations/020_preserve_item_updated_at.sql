@@ -0,0 +1,17 @@
+-- Recomputing search_vector, as the reviews trigger does when a review
+-- changes, is not an edit of the item: leave updated_at alone unless some
+-- other column changed
+CREATE OR REPLACE FUNCTION media_items_update_updated_at() RETURNS trigger AS $$
+BEGIN
+    IF to_jsonb(NEW) - ARRAY['updated_at', 'search_vector']
+        IS DISTINCT FROM to_jsonb(OLD) - ARRAY['updated_at', 'search_vector'] THEN
+        NEW.updated_at = now();
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS media_items_updated_at ON media_items;
+CREATE TRIGGER media_items_updated_at
+    BEFORE UPDATE ON media_items
+    FOR EACH ROW EXECUTE FUNCTION media_items_update_updated_at();
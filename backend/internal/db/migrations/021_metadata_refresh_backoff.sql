@@ -0,0 +1,16 @@
+-- Failed metadata refreshes back off instead of being retried every run
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS metadata_refresh_failures INT NOT NULL DEFAULT 0;
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS metadata_retry_at TIMESTAMPTZ;
+
+-- Refresh bookkeeping is not an edit of the item either
+CREATE OR REPLACE FUNCTION media_items_update_updated_at() RETURNS trigger AS $$
+DECLARE
+    ignored TEXT[] := ARRAY['updated_at', 'search_vector',
+        'metadata_refreshed_at', 'metadata_refresh_failures', 'metadata_retry_at'];
+BEGIN
+    IF to_jsonb(NEW) - ignored IS DISTINCT FROM to_jsonb(OLD) - ignored THEN
+        NEW.updated_at = now();
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
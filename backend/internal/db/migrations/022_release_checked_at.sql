@@ -0,0 +1,17 @@
+-- Release date refreshes work through the wishlist in the order items were
+-- last checked, so a few items are not looked up on every run
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS release_checked_at TIMESTAMPTZ;
+
+-- Checking a release date is not an edit of the item
+CREATE OR REPLACE FUNCTION media_items_update_updated_at() RETURNS trigger AS $$
+DECLARE
+    ignored TEXT[] := ARRAY['updated_at', 'search_vector',
+        'metadata_refreshed_at', 'metadata_refresh_failures', 'metadata_retry_at',
+        'release_checked_at'];
+BEGIN
+    IF to_jsonb(NEW) - ignored IS DISTINCT FROM to_jsonb(OLD) - ignored THEN
+        NEW.updated_at = now();
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
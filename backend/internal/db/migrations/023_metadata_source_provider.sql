@@ -0,0 +1,11 @@
+-- Enrichment used to record the item's media type as its source; it now
+-- records the provider, so old values become that type's native provider
+ALTER TABLE media_items DISABLE TRIGGER media_items_updated_at;
+UPDATE media_items
+SET metadata = jsonb_set(metadata, '{source}', to_jsonb(CASE metadata->>'source'
+    WHEN 'movie' THEN 'tmdb'
+    WHEN 'music' THEN 'musicbrainz'
+    WHEN 'game' THEN 'igdb'
+END))
+WHERE metadata->>'source' IN ('movie', 'music', 'game');
+ALTER TABLE media_items ENABLE TRIGGER media_items_updated_at;
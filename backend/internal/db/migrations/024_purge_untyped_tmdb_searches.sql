@@ -0,0 +1,4 @@
+-- TMDB search results were cached without a media type and are now dropped
+-- as untyped, so discard them and let the next search refetch
+DELETE FROM metadata_cache
+WHERE provider = 'tmdb' AND operation = 'search' AND response IS NOT NULL;
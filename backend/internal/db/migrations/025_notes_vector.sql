@@ -0,0 +1,41 @@
+-- Notes get their own indexed vector, kept apart from search_vector so
+-- queries can match the notes a viewer may read without parsing them per row
+ALTER TABLE media_items ADD COLUMN IF NOT EXISTS notes_vector TSVECTOR;
+CREATE INDEX IF NOT EXISTS idx_media_notes_search ON media_items USING GIN (notes_vector);
+
+-- Weighted: title A, creator B, genre C, overview D, reviews D; notes D apart
+CREATE OR REPLACE FUNCTION media_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.creator, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(array_to_string(NEW.genre, ' '), '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.metadata->>'overview', '')), 'D') ||
+        setweight(to_tsvector('english', coalesce((
+            SELECT string_agg(r.search_text, ' ')
+            FROM reviews r
+            WHERE r.media_item_id = NEW.id AND r.published
+        ), '')), 'D');
+    NEW.notes_vector := setweight(to_tsvector('english', coalesce(NEW.notes, '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- The notes vector follows the notes and is not an edit of its own
+CREATE OR REPLACE FUNCTION media_items_update_updated_at() RETURNS trigger AS $$
+DECLARE
+    ignored TEXT[] := ARRAY['updated_at', 'search_vector', 'notes_vector',
+        'metadata_refreshed_at', 'metadata_refresh_failures', 'metadata_retry_at',
+        'release_checked_at'];
+BEGIN
+    IF to_jsonb(NEW) - ignored IS DISTINCT FROM to_jsonb(OLD) - ignored THEN
+        NEW.updated_at = now();
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Fill the vector for existing notes without bumping updated_at
+ALTER TABLE media_items DISABLE TRIGGER media_items_updated_at;
+UPDATE media_items SET notes_vector = NULL WHERE notes <> '';
+ALTER TABLE media_items ENABLE TRIGGER media_items_updated_at;
@@ -0,0 +1,80 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// bigramWeight is the contribution of a word pair relative to a single word.
+const bigramWeight = 0.5
+
+// HashEmbedder is a deterministic local embedder using the hashing trick:
+// each word and adjacent word pair is hashed to a signed dimension and the
+// result is L2-normalized. It needs no network access, so it suits tests and
+// offline installs, but it only captures shared vocabulary, not meaning.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a HashEmbedder producing vectors of dims dimensions.
+func NewHashEmbedder(dims int) *HashEmbedder {
+	return &HashEmbedder{dims: dims}
+}
+
+// Model returns the embedding space identifier.
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", e.dims)
+}
+
+// Embed hashes each text into a vector.
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([]Vector, error) {
+	vectors := make([]Vector, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) Vector {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		// Keep the vector non-zero; a zero vector has no cosine distance.
+		words = []string{strings.ToLower(text)}
+	}
+
+	v := make(Vector, e.dims)
+	for i, w := range words {
+		e.add(v, w, 1)
+		if i > 0 {
+			e.add(v, words[i-1]+" "+w, bigramWeight)
+		}
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] = float32(float64(v[i]) / norm)
+	}
+	return v
+}
+
+// add hashes token to a dimension and adds weight to it, with the sign
+// taken from another bit of the hash so collisions tend to cancel out.
+func (e *HashEmbedder) add(v Vector, token string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(token)) //nolint:errcheck
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(e.dims)] += weight
+}
@@ -0,0 +1,100 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func cosine(a, b Vector) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder(64)
+	if got := e.Model(); got != "hash-64" {
+		t.Errorf("Model() = %q, want %q", got, "hash-64")
+	}
+
+	texts := []string{"Bleak sci-fi about isolation", "", "?!", "bleak SCI-FI, about isolation."}
+	first, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+	second, _ := e.Embed(context.Background(), texts)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Embed is not deterministic")
+	}
+
+	for i, v := range first {
+		if len(v) != 64 {
+			t.Errorf("vector %d has %d dimensions, want 64", i, len(v))
+		}
+		if norm := math.Sqrt(cosine(v, v)); math.Abs(norm-1) > 1e-6 {
+			t.Errorf("vector %d (%q) has norm %f, want 1", i, texts[i], norm)
+		}
+	}
+	if !reflect.DeepEqual(first[0], first[3]) {
+		t.Error("case and punctuation changed the vector")
+	}
+}
+
+func TestHashEmbedderSimilarity(t *testing.T) {
+	e := NewHashEmbedder(256)
+	vectors, err := e.Embed(context.Background(), []string{
+		"lonely astronaut stranded on mars",
+		"astronaut stranded alone on mars",
+		"cheerful cooking show with baking contests",
+	})
+	if err != nil {
+		t.Fatalf("Embed error: %v", err)
+	}
+
+	near, far := cosine(vectors[0], vectors[1]), cosine(vectors[0], vectors[2])
+	if near <= far {
+		t.Errorf("similarity of related texts %f is not above unrelated %f", near, far)
+	}
+}
+
+func TestVectorString(t *testing.T) {
+	if got := (Vector{0.5, -1, 0}).String(); got != "[0.5,-1,0]" {
+		t.Errorf("String() = %q, want %q", got, "[0.5,-1,0]")
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	tests := []struct {
+		name string
+		src  source
+		want string
+	}{
+		{
+			name: "title only",
+			src:  source{Title: "Moon", Overview: "  ", Notes: "\n"},
+			want: "Moon",
+		},
+		{
+			name: "every field",
+			src: source{
+				Title:    "Moon",
+				Genre:    []string{"Sci-Fi", "Drama"},
+				Overview: " A miner nears the end of his contract. ",
+				Notes:    "rewatch",
+			},
+			want: "Moon\nSci-Fi, Drama\nA miner nears the end of his contract.\nrewatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := documentText(tt.src); got != tt.want {
+				t.Errorf("documentText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
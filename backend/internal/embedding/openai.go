@@ -0,0 +1,101 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPEmbedder calls an OpenAI-compatible /embeddings endpoint, which covers
+// OpenAI itself as well as local servers such as Ollama and LM Studio.
+type HTTPEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dims       int
+	httpClient *http.Client
+}
+
+// NewHTTPEmbedder creates an HTTPEmbedder. dims is sent as the requested
+// output dimension when positive; apiKey may be empty for local servers.
+func NewHTTPEmbedder(baseURL, apiKey, model string, dims int) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dims:       dims,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Model returns the embedding space identifier, which includes the
+// requested dimension since it changes the vectors.
+func (e *HTTPEmbedder) Model() string {
+	if e.dims > 0 {
+		return fmt.Sprintf("%s@%d", e.model, e.dims)
+	}
+	return e.model
+}
+
+type embeddingsRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed requests vectors for all texts in one call.
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([]Vector, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: e.model, Input: texts, Dimensions: e.dims})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings request: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode embeddings: %w", err)
+	}
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings: got %d vectors for %d inputs", len(out.Data), len(texts))
+	}
+
+	vectors := make([]Vector, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings: index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,90 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles database operations for item embeddings.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new embedding Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// source is the embeddable content of an item along with the hash of the
+// content its stored vector was built from, if any.
+type source struct {
+	ItemID     uuid.UUID
+	Title      string
+	Genre      []string
+	Overview   string
+	Notes      string
+	StoredHash string
+}
+
+// ListStale returns up to limit items, newest first, whose vector for model
+// is missing or older than the item. Private notes on shared items are left
+// out, since every member searches against the same vector.
+func (r *Repository) ListStale(ctx context.Context, model string, limit int) ([]source, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.id, m.title, m.genre, coalesce(m.metadata->>'overview', ''),
+			CASE WHEN m.collection_id IS NULL OR NOT m.notes_private THEN m.notes ELSE '' END,
+			coalesce(e.content_hash, '')
+		FROM media_items m
+		LEFT JOIN media_embeddings e ON e.media_item_id = m.id AND e.model = $1
+		WHERE e.media_item_id IS NULL OR e.updated_at < m.updated_at
+		ORDER BY m.updated_at DESC
+		LIMIT $2`,
+		model, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list stale embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []source
+	for rows.Next() {
+		var s source
+		if err := rows.Scan(&s.ItemID, &s.Title, &s.Genre, &s.Overview, &s.Notes, &s.StoredHash); err != nil {
+			return nil, fmt.Errorf("scan embedding source: %w", err)
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// Upsert stores an item's vector for model.
+func (r *Repository) Upsert(ctx context.Context, itemID uuid.UUID, model string, v Vector, contentHash string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO media_embeddings (media_item_id, model, embedding, content_hash)
+		VALUES ($1, $2, $3::vector, $4)
+		ON CONFLICT (media_item_id, model) DO UPDATE SET
+			embedding = EXCLUDED.embedding,
+			content_hash = EXCLUDED.content_hash,
+			updated_at = now()`,
+		itemID, model, v.String(), contentHash,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert embedding: %w", err)
+	}
+	return nil
+}
+
+// Touch marks an item's vector for model as current without changing it.
+func (r *Repository) Touch(ctx context.Context, itemID uuid.UUID, model string) error {
+	_, err := r.db.Exec(ctx,
+		"UPDATE media_embeddings SET updated_at = now() WHERE media_item_id=$1 AND model=$2",
+		itemID, model,
+	)
+	if err != nil {
+		return fmt.Errorf("touch embedding: %w", err)
+	}
+	return nil
+}
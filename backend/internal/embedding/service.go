@@ -0,0 +1,125 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Refresh limits. A large backlog is embedded newest first and worked
+// through over several runs.
+const (
+	batchSize       = 64
+	maxRefreshItems = 512
+)
+
+// Service keeps item vectors current and embeds search queries.
+type Service struct {
+	repo     *Repository
+	embedder Embedder
+}
+
+// NewService creates a new embedding Service.
+func NewService(repo *Repository, embedder Embedder) *Service {
+	return &Service{repo: repo, embedder: embedder}
+}
+
+// Model returns the embedding model vectors are stored under.
+func (s *Service) Model() string {
+	return s.embedder.Model()
+}
+
+// Refresh embeds up to maxRefreshItems items whose vectors are missing or
+// out of date and returns the number of items embedded. Items whose embedded
+// content is unchanged, such as after a status change, are marked current
+// without calling the embedder.
+func (s *Service) Refresh(ctx context.Context) (int, error) {
+	sources, err := s.repo.ListStale(ctx, s.Model(), maxRefreshItems)
+	if err != nil {
+		return 0, err
+	}
+
+	var pending []source
+	var texts, hashes []string
+	for _, src := range sources {
+		text := documentText(src)
+		sum := sha256.Sum256([]byte(text))
+		hash := hex.EncodeToString(sum[:])
+		if hash == src.StoredHash {
+			if err := s.repo.Touch(ctx, src.ItemID, s.Model()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		pending = append(pending, src)
+		texts = append(texts, text)
+		hashes = append(hashes, hash)
+	}
+
+	embedded := 0
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		vectors, err := s.embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			return embedded, fmt.Errorf("embed items: %w", err)
+		}
+		for i, v := range vectors {
+			if err := s.repo.Upsert(ctx, pending[start+i].ItemID, s.Model(), v, hashes[start+i]); err != nil {
+				return embedded, err
+			}
+			embedded++
+		}
+	}
+	return embedded, nil
+}
+
+// RunRefresh embeds stale items once at start and then every interval until
+// ctx is cancelled, so semantic searches never wait on the embedder for
+// anything but the query.
+func RunRefresh(ctx context.Context, svc *Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := svc.Refresh(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("embedding refresh run failed", "error", err)
+		} else if n > 0 {
+			slog.Info("embedding refresh run", "embedded", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EmbedQuery returns the vector of a search query.
+func (s *Service) EmbedQuery(ctx context.Context, query string) (Vector, error) {
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	return vectors[0], nil
+}
+
+// documentText is the text embedded for an item: its title, genres,
+// provider overview and notes.
+func documentText(src source) string {
+	parts := []string{src.Title}
+	if len(src.Genre) > 0 {
+		parts = append(parts, strings.Join(src.Genre, ", "))
+	}
+	for _, p := range []string{src.Overview, src.Notes} {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
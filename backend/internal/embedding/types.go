@@ -0,0 +1,37 @@
+// Package embedding provides vector embeddings of media items for semantic
+// search, stored in pgvector.
+package embedding
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Embedder turns texts into vectors of a fixed dimension.
+type Embedder interface {
+	// Embed returns one vector per input text, in order.
+	Embed(ctx context.Context, texts []string) ([]Vector, error)
+	// Model identifies the embedding space. Vectors from different models
+	// are never compared.
+	Model() string
+}
+
+// Vector is an embedding.
+type Vector []float32
+
+// String formats the vector in pgvector's text form, e.g. "[0.1,0.2]", so it
+// can be bound as a parameter and cast with ::vector.
+func (v Vector) String() string {
+	var b strings.Builder
+	b.Grow(len(v) * 10)
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
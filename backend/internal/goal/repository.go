@@ -0,0 +1,201 @@
+package goal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/your-org/ems/internal/media"
+)
+
+// Repository handles database operations for goals.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new goal Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const goalColumns = `id, user_id, title, metric, target_count, media_type, genre,
+	period_start, period_end, created_at, updated_at`
+
+func scanGoal(row pgx.Row) (*Goal, error) {
+	var g Goal
+	var start, end time.Time
+	err := row.Scan(
+		&g.ID, &g.UserID, &g.Title, &g.Metric, &g.TargetCount, &g.MediaType, &g.Genre,
+		&start, &end, &g.CreatedAt, &g.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	g.PeriodStart = media.Date{Time: start}
+	g.PeriodEnd = media.Date{Time: end}
+	return &g, nil
+}
+
+// Create inserts a new goal.
+func (r *Repository) Create(ctx context.Context, g *Goal) (*Goal, error) {
+	row := r.db.QueryRow(ctx, `
+		INSERT INTO goals (user_id, title, metric, target_count, media_type, genre, period_start, period_end)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING `+goalColumns,
+		g.UserID, g.Title, g.Metric, g.TargetCount, g.MediaType, g.Genre,
+		g.PeriodStart.Time, g.PeriodEnd.Time,
+	)
+	created, err := scanGoal(row)
+	if err != nil {
+		return nil, fmt.Errorf("insert goal: %w", err)
+	}
+	return created, nil
+}
+
+// GetByID fetches a goal owned by userID.
+func (r *Repository) GetByID(ctx context.Context, id, userID uuid.UUID) (*Goal, error) {
+	row := r.db.QueryRow(ctx,
+		`SELECT `+goalColumns+` FROM goals WHERE id=$1 AND user_id=$2`, id, userID,
+	)
+	g, err := scanGoal(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query goal: %w", err)
+	}
+	return g, nil
+}
+
+// List returns the user's goals, most recent period first.
+func (r *Repository) List(ctx context.Context, userID uuid.UUID) ([]*Goal, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+goalColumns+` FROM goals WHERE user_id=$1 ORDER BY period_end DESC, created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := make([]*Goal, 0)
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan goal: %w", err)
+		}
+		goals = append(goals, g)
+	}
+	return goals, rows.Err()
+}
+
+// Update modifies a goal's fields. The resulting period must not end before
+// it starts, whichever of its bounds the request changes.
+func (r *Repository) Update(ctx context.Context, id, userID uuid.UUID, req UpdateRequest) (*Goal, error) {
+	sets := []string{}
+	args := []any{}
+	argIdx := 1
+	start, end := "period_start", "period_end"
+
+	if req.Title != nil {
+		sets = append(sets, fmt.Sprintf("title=$%d", argIdx))
+		args = append(args, *req.Title)
+		argIdx++
+	}
+	if req.TargetCount != nil {
+		sets = append(sets, fmt.Sprintf("target_count=$%d", argIdx))
+		args = append(args, *req.TargetCount)
+		argIdx++
+	}
+	if req.PeriodStart != nil {
+		start = fmt.Sprintf("$%d::date", argIdx)
+		sets = append(sets, "period_start="+start)
+		args = append(args, req.PeriodStart.Time)
+		argIdx++
+	}
+	if req.PeriodEnd != nil {
+		end = fmt.Sprintf("$%d::date", argIdx)
+		sets = append(sets, "period_end="+end)
+		args = append(args, req.PeriodEnd.Time)
+		argIdx++
+	}
+
+	if len(sets) == 0 {
+		return r.GetByID(ctx, id, userID)
+	}
+
+	args = append(args, id, userID)
+	row := r.db.QueryRow(ctx, fmt.Sprintf(
+		`UPDATE goals SET %s WHERE id=$%d AND user_id=$%d AND %s <= %s RETURNING `+goalColumns,
+		strings.Join(sets, ","), argIdx, argIdx+1, start, end,
+	), args...)
+	g, err := scanGoal(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// Either the goal does not exist or the new period is inverted.
+			if _, err := r.GetByID(ctx, id, userID); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: period_end must not be before period_start", ErrInvalidGoal)
+		}
+		return nil, fmt.Errorf("update goal: %w", err)
+	}
+	return g, nil
+}
+
+// Delete removes a goal.
+func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM goals WHERE id=$1 AND user_id=$2", id, userID)
+	if err != nil {
+		return fmt.Errorf("delete goal: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Count returns how many distinct items satisfy the goal's metric and filters
+// within its period. Transitions are read from media_status_history.
+func (r *Repository) Count(ctx context.Context, g *Goal) (int, error) {
+	var query string
+	switch g.Metric {
+	case MetricAdded:
+		query = `
+			SELECT COUNT(*) FROM media_items m
+			WHERE m.user_id = $1
+			AND m.created_at >= $2::date AND m.created_at < $3::date + 1`
+	case MetricStarted, MetricCompleted:
+		status := media.StatusCompleted
+		if g.Metric == MetricStarted {
+			status = media.StatusCurrentlyUsing
+		}
+		query = `
+			SELECT COUNT(DISTINCT h.media_item_id)
+			FROM media_status_history h JOIN media_items m ON m.id = h.media_item_id
+			WHERE h.user_id = $1 AND h.to_status = '` + string(status) + `'
+			AND h.changed_at >= $2::date AND h.changed_at < $3::date + 1`
+	default:
+		return 0, fmt.Errorf("unknown metric: %s", g.Metric)
+	}
+
+	args := []any{g.UserID, g.PeriodStart.Time, g.PeriodEnd.Time}
+	if g.MediaType != nil {
+		args = append(args, *g.MediaType)
+		query += fmt.Sprintf(" AND m.media_type = $%d", len(args))
+	}
+	if g.Genre != nil {
+		args = append(args, *g.Genre)
+		query += fmt.Sprintf(" AND $%d = ANY(m.genre)", len(args))
+	}
+
+	var n int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count goal progress: %w", err)
+	}
+	return n, nil
+}
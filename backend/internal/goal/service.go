@@ -0,0 +1,194 @@
+package goal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/media"
+)
+
+// ErrInvalidGoal is returned when a goal fails validation.
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// ErrNotFound is returned for goals that do not exist or belong to someone
+// else.
+var ErrNotFound = errors.New("goal not found")
+
+// paceTolerance is how many items progress may drift from the linear
+// schedule while still counting as on track.
+const paceTolerance = 0.5
+
+// Service manages goals and computes their progress.
+type Service struct {
+	repo *Repository
+	now  func() time.Time
+}
+
+// NewService creates a new goal Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Create validates and stores a new goal. Validation errors wrap
+// ErrInvalidGoal.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Progress, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidGoal)
+	}
+	if req.TargetCount <= 0 {
+		return nil, fmt.Errorf("%w: target_count must be positive", ErrInvalidGoal)
+	}
+	if req.Metric == "" {
+		req.Metric = MetricCompleted
+	}
+	switch req.Metric {
+	case MetricCompleted, MetricStarted, MetricAdded:
+	default:
+		return nil, fmt.Errorf("%w: metric must be completed, started or added", ErrInvalidGoal)
+	}
+	if req.MediaType != nil && !req.MediaType.Valid() {
+		return nil, fmt.Errorf("%w: media_type must be movie, music or game", ErrInvalidGoal)
+	}
+
+	year := s.now().Year()
+	if req.Year != nil {
+		year = *req.Year
+	}
+	start := media.Date{Time: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	end := media.Date{Time: time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)}
+	if req.PeriodStart != nil {
+		start = *req.PeriodStart
+	}
+	if req.PeriodEnd != nil {
+		end = *req.PeriodEnd
+	}
+	if end.Before(start.Time) {
+		return nil, fmt.Errorf("%w: period_end must not be before period_start", ErrInvalidGoal)
+	}
+
+	g, err := s.repo.Create(ctx, &Goal{
+		UserID:      userID,
+		Title:       strings.TrimSpace(req.Title),
+		Metric:      req.Metric,
+		TargetCount: req.TargetCount,
+		MediaType:   req.MediaType,
+		Genre:       req.Genre,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, g)
+}
+
+// Get returns a goal with its progress.
+func (s *Service) Get(ctx context.Context, id, userID uuid.UUID) (*Progress, error) {
+	g, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, g)
+}
+
+// List returns all of the user's goals with their progress.
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Progress, error) {
+	goals, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Progress, 0, len(goals))
+	for _, g := range goals {
+		p, err := s.progress(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Update modifies a goal and returns its refreshed progress. Validation
+// errors wrap ErrInvalidGoal.
+func (s *Service) Update(ctx context.Context, id, userID uuid.UUID, req UpdateRequest) (*Progress, error) {
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: title is required", ErrInvalidGoal)
+		}
+		req.Title = &title
+	}
+	if req.TargetCount != nil && *req.TargetCount <= 0 {
+		return nil, fmt.Errorf("%w: target_count must be positive", ErrInvalidGoal)
+	}
+	g, err := s.repo.Update(ctx, id, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, g)
+}
+
+// Delete removes a goal.
+func (s *Service) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *Service) progress(ctx context.Context, g *Goal) (*Progress, error) {
+	current, err := s.repo.Count(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	return computePace(g, current, s.now()), nil
+}
+
+// computePace compares progress with a linear schedule across the period.
+// Both period bounds are inclusive whole days.
+func computePace(g *Goal, current int, now time.Time) *Progress {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysTotal := int(g.PeriodEnd.Sub(g.PeriodStart.Time).Hours()/24) + 1
+	daysElapsed := int(today.Sub(g.PeriodStart.Time).Hours()/24) + 1
+	if daysElapsed < 0 {
+		daysElapsed = 0
+	}
+	if daysElapsed > daysTotal {
+		daysElapsed = daysTotal
+	}
+
+	p := &Progress{
+		Goal:            g,
+		Current:         current,
+		Remaining:       max(g.TargetCount-current, 0),
+		DaysElapsed:     daysElapsed,
+		DaysTotal:       daysTotal,
+		PercentComplete: math.Round(float64(current)/float64(g.TargetCount)*1000) / 10,
+	}
+
+	fraction := float64(daysElapsed) / float64(daysTotal)
+	p.Expected = math.Round(float64(g.TargetCount)*fraction*10) / 10
+	if fraction > 0 {
+		p.Projected = int(math.Round(float64(current) / fraction))
+	}
+	if weeksLeft := float64(daysTotal-daysElapsed) / 7; weeksLeft > 0 {
+		p.NeededPerWeek = math.Round(float64(p.Remaining)/weeksLeft*10) / 10
+	}
+
+	diff := float64(current) - float64(g.TargetCount)*fraction
+	switch {
+	case current >= g.TargetCount:
+		p.Pace = PaceAchieved
+	case today.After(g.PeriodEnd.Time):
+		p.Pace = PaceMissed
+	case diff >= paceTolerance:
+		p.Pace = PaceAhead
+	case diff <= -paceTolerance:
+		p.Pace = PaceBehind
+	default:
+		p.Pace = PaceOnTrack
+	}
+	return p
+}
@@ -0,0 +1,44 @@
+package goal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/media"
+)
+
+func TestCreateValidation(t *testing.T) {
+	book := media.MediaType("book")
+	movie := media.MediaTypeMovie
+	year := 2024
+	valid := CreateRequest{Title: "Films", TargetCount: 12, MediaType: &movie, Year: &year}
+
+	tests := []struct {
+		name   string
+		modify func(*CreateRequest)
+	}{
+		{"blank title", func(r *CreateRequest) { r.Title = " " }},
+		{"no target", func(r *CreateRequest) { r.TargetCount = 0 }},
+		{"unknown metric", func(r *CreateRequest) { r.Metric = "reviewed" }},
+		{"unknown media type", func(r *CreateRequest) { r.MediaType = &book }},
+		{"period ends before it starts", func(r *CreateRequest) {
+			r.PeriodStart = &media.Date{}
+			r.PeriodEnd = &media.Date{}
+			r.PeriodStart.Time = r.PeriodStart.AddDate(1, 0, 0)
+		}},
+	}
+
+	// Invalid requests fail before the repository is used.
+	s := NewService(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			if _, err := s.Create(context.Background(), uuid.New(), req); !errors.Is(err, ErrInvalidGoal) {
+				t.Errorf("Create error = %v, want ErrInvalidGoal", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package goal provides yearly goals and challenges with pace tracking.
+package goal
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/media"
+)
+
+// Metric is the event a goal counts.
+type Metric string
+
+const (
+	// MetricCompleted counts items moved to completed during the period.
+	MetricCompleted Metric = "completed"
+	// MetricStarted counts items moved to currently_using during the period.
+	MetricStarted Metric = "started"
+	// MetricAdded counts items added to the collection during the period.
+	MetricAdded Metric = "added"
+)
+
+// Pace describes how progress compares with a linear schedule.
+type Pace string
+
+const (
+	PaceAhead    Pace = "ahead"
+	PaceOnTrack  Pace = "on_track"
+	PaceBehind   Pace = "behind"
+	PaceAchieved Pace = "achieved"
+	PaceMissed   Pace = "missed"
+)
+
+// Goal is a target count of items over a period, optionally filtered by type and genre.
+type Goal struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	Title       string           `json:"title"`
+	Metric      Metric           `json:"metric"`
+	TargetCount int              `json:"target_count"`
+	MediaType   *media.MediaType `json:"media_type,omitempty"`
+	Genre       *string          `json:"genre,omitempty"`
+	PeriodStart media.Date       `json:"period_start"`
+	PeriodEnd   media.Date       `json:"period_end"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// Progress is a goal with its current count and pace.
+type Progress struct {
+	*Goal
+	Current         int     `json:"current"`
+	Remaining       int     `json:"remaining"`
+	Expected        float64 `json:"expected"`
+	Projected       int     `json:"projected"`
+	Pace            Pace    `json:"pace"`
+	DaysElapsed     int     `json:"days_elapsed"`
+	DaysTotal       int     `json:"days_total"`
+	NeededPerWeek   float64 `json:"needed_per_week"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// CreateRequest is the payload for creating a goal. The period defaults to
+// the calendar year given by Year, or the current year.
+type CreateRequest struct {
+	Title       string           `json:"title"`
+	Metric      Metric           `json:"metric"`
+	TargetCount int              `json:"target_count"`
+	MediaType   *media.MediaType `json:"media_type,omitempty"`
+	Genre       *string          `json:"genre,omitempty"`
+	Year        *int             `json:"year,omitempty"`
+	PeriodStart *media.Date      `json:"period_start,omitempty"`
+	PeriodEnd   *media.Date      `json:"period_end,omitempty"`
+}
+
+// UpdateRequest is the payload for updating a goal.
+type UpdateRequest struct {
+	Title       *string     `json:"title,omitempty"`
+	TargetCount *int        `json:"target_count,omitempty"`
+	PeriodStart *media.Date `json:"period_start,omitempty"`
+	PeriodEnd   *media.Date `json:"period_end,omitempty"`
+}
@@ -0,0 +1,133 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// MaxDiscoverOwners caps the owners listed for each discovered title.
+const MaxDiscoverOwners = 10
+
+// discoverMatches selects one row per owner and title for items on public
+// profiles that the viewer ($1) may see and whose title or creator matches
+// the query ($2, or the LIKE pattern $3) with at least the fuzzy threshold,
+// which setFuzzyThreshold sets on the querying transaction. Only the title and creator are matched, not search_vector, so a
+// title is found by what it is rather than by what owners wrote about it.
+const discoverMatches = `
+	SELECT DISTINCT ON (m.user_id, m.media_type, lower(m.title))
+		m.user_id, m.title, m.media_type, m.creator, m.release_year, m.cover_url,
+		m.status, m.rating, u.username, u.display_name, u.avatar_url,
+		` + fuzzyScoreExpr + ` AS score
+	FROM media_items m
+	JOIN users u ON u.id = m.user_id
+	WHERE u.is_public AND (
+		m.visibility = 'public'
+		OR (m.visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows f WHERE f.followee_id = m.user_id AND f.follower_id = $1 AND f.accepted
+		))
+	) AND (
+		m.title ILIKE $3 OR m.creator ILIKE $3 OR $2 <%% m.title OR $2 <%% m.creator
+	)%s
+	ORDER BY m.user_id, m.media_type, lower(m.title), m.rating DESC NULLS LAST`
+
+// discoverTitles groups the matches by media type and case-folded title, so
+// every copy of a work counts towards the same owners and average rating.
+const discoverTitles = `
+	SELECT media_type,
+		mode() WITHIN GROUP (ORDER BY title) AS title,
+		mode() WITHIN GROUP (ORDER BY creator) AS creator,
+		mode() WITHIN GROUP (ORDER BY release_year) AS release_year,
+		COALESCE((array_agg(cover_url) FILTER (WHERE cover_url <> ''))[1], '') AS cover_url,
+		COUNT(*) AS owner_count,
+		round(AVG(rating), 1)::float8 AS average_rating,
+		COUNT(rating) AS rating_count,
+		MAX(score) AS score,
+		jsonb_agg(jsonb_build_object(
+			'username', username,
+			'display_name', display_name,
+			'avatar_url', avatar_url,
+			'status', status,
+			'rating', rating
+		) ORDER BY rating DESC NULLS LAST, username) AS owners
+	FROM matches
+	GROUP BY media_type, lower(title)`
+
+// Discover searches the public items of every public profile and returns one
+// page of matching titles, most relevant first. Each title lists the users
+// who hold it and their average rating. Item visibility is honoured the same
+// way as on profiles: followers-only items match only for followers, and
+// private items and private profiles never match.
+func (r *Repository) Discover(ctx context.Context, f DiscoverFilter) (*DiscoverResult, error) {
+	if f.PageSize <= 0 {
+		f.PageSize = 20
+	}
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+
+	filter := ""
+	args := []any{f.ViewerID, f.Query, "%" + EscapeLike(f.Query) + "%"}
+	if len(f.MediaTypes) > 0 {
+		types := make([]string, len(f.MediaTypes))
+		for i, t := range f.MediaTypes {
+			types[i] = string(t)
+		}
+		filter = " AND m.media_type::text = ANY($4)"
+		args = append(args, types)
+	}
+	with := fmt.Sprintf("WITH matches AS (%s), titles AS (%s)", fmt.Sprintf(discoverMatches, filter), discoverTitles)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := setFuzzyThreshold(ctx, tx, r.fuzzyThreshold); err != nil {
+		return nil, err
+	}
+
+	result := &DiscoverResult{Titles: make([]*DiscoverTitle, 0)}
+	if err := tx.QueryRow(ctx, with+" SELECT COUNT(*) FROM titles", args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("count discover: %w", err)
+	}
+
+	n := len(args)
+	query := fmt.Sprintf(`%s
+		SELECT title, media_type, creator, release_year, cover_url, owner_count,
+			average_rating, rating_count, score,
+			jsonb_path_query_array(owners, '$[0 to %d]')
+		FROM titles
+		ORDER BY score DESC, owner_count DESC, title
+		LIMIT $%d OFFSET $%d`,
+		with, MaxDiscoverOwners-1, n+1, n+2,
+	)
+	args = append(args, f.PageSize, (f.Page-1)*f.PageSize)
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("discover titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t DiscoverTitle
+		var ownersJSON []byte
+		if err := rows.Scan(
+			&t.Title, &t.MediaType, &t.Creator, &t.ReleaseYear, &t.CoverURL, &t.OwnerCount,
+			&t.AverageRating, &t.RatingCount, &t.Score, &ownersJSON,
+		); err != nil {
+			return nil, fmt.Errorf("scan discovered title: %w", err)
+		}
+		if err := json.Unmarshal(ownersJSON, &t.Owners); err != nil {
+			return nil, fmt.Errorf("unmarshal owners: %w", err)
+		}
+		t.Score = math.Round(t.Score*1000) / 1000
+		result.Titles = append(result.Titles, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
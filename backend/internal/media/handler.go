@@ -2,8 +2,12 @@ package media
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -42,6 +46,47 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	if g := r.URL.Query().Get("genre"); g != "" {
 		f.Genre = &g
 	}
+	if t := r.URL.Query().Get("tag"); t != "" {
+		f.Tag = &t
+	}
+	if c := r.URL.Query().Get("creator"); c != "" {
+		f.Creator = &c
+	}
+	if q := r.URL.Query().Get("q"); q != "" {
+		f.TextQuery = &q
+	}
+	for _, p := range []struct {
+		key string
+		dst **int
+	}{
+		{"year_min", &f.ReleaseYearMin},
+		{"year_max", &f.ReleaseYearMax},
+	} {
+		if v := r.URL.Query().Get(p.key); v != "" {
+			y, err := strconv.Atoi(v)
+			if err != nil {
+				httputil.WriteError(w, http.StatusBadRequest, "invalid "+p.key)
+				return
+			}
+			*p.dst = &y
+		}
+	}
+	if v := r.URL.Query().Get("rating_min"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(rating) || math.IsInf(rating, 0) {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid rating_min")
+			return
+		}
+		f.RatingMin = &rating
+	}
+	if c := r.URL.Query().Get("collection"); c != "" {
+		cid, err := uuid.Parse(c)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid collection")
+			return
+		}
+		f.CollectionID = &cid
+	}
 
 	items, total, err := h.svc.List(r.Context(), f)
 	if err != nil {
@@ -72,10 +117,18 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.Status == "" {
 		req.Status = StatusOwned
 	}
+	if req.Visibility != "" && !req.Visibility.Valid() {
+		httputil.WriteError(w, http.StatusBadRequest, "visibility must be public, followers or private")
+		return
+	}
+	if negativeMinutes(req.RuntimeMinutes, req.DurationMinutes, req.TimeToBeatMinutes) {
+		httputil.WriteError(w, http.StatusBadRequest, negativeMinutesMessage)
+		return
+	}
 
 	item, err := h.svc.Create(r.Context(), claims.UserID, req)
 	if err != nil {
-		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeItemError(w, err)
 		return
 	}
 
@@ -93,7 +146,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 
 	item, err := h.svc.GetByID(r.Context(), id, claims.UserID)
 	if err != nil {
-		httputil.WriteError(w, http.StatusNotFound, err.Error())
+		writeItemError(w, err)
 		return
 	}
 
@@ -114,10 +167,18 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if req.Visibility != nil && !req.Visibility.Valid() {
+		httputil.WriteError(w, http.StatusBadRequest, "visibility must be public, followers or private")
+		return
+	}
+	if negativeMinutes(req.RuntimeMinutes, req.DurationMinutes, req.TimeToBeatMinutes) {
+		httputil.WriteError(w, http.StatusBadRequest, negativeMinutesMessage)
+		return
+	}
 
 	item, err := h.svc.Update(r.Context(), id, claims.UserID, req)
 	if err != nil {
-		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeItemError(w, err)
 		return
 	}
 
@@ -134,7 +195,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Delete(r.Context(), id, claims.UserID); err != nil {
-		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeItemError(w, err)
 		return
 	}
 
@@ -157,14 +218,300 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	item, err := h.svc.UpdateStatus(r.Context(), id, claims.UserID, req.Status)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, item)
+}
+
+// SetCollection handles PUT /api/media/:id/collection.
+func (h *Handler) SetCollection(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	item, err := h.svc.SetCollection(r.Context(), id, claims.UserID, req.CollectionID)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, item)
+}
+
+// LinkExternal handles PUT /api/media/:id/external/:provider.
+func (h *Handler) LinkExternal(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	provider := chi.URLParam(r, "provider")
+	if !ValidExternalProvider(provider) {
+		httputil.WriteError(w, http.StatusBadRequest, "provider must be one of "+strings.Join(ExternalProviders, ", "))
+		return
+	}
+
+	var req ExternalIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ExternalID = strings.TrimSpace(req.ExternalID)
+	if req.ExternalID == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "external_id is required")
+		return
+	}
+
+	item, err := h.svc.LinkExternalID(r.Context(), id, claims.UserID, provider, req.ExternalID)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, item)
+}
+
+// UnlinkExternal handles DELETE /api/media/:id/external/:provider.
+func (h *Handler) UnlinkExternal(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	provider := chi.URLParam(r, "provider")
+	if !ValidExternalProvider(provider) {
+		httputil.WriteError(w, http.StatusBadRequest, "provider must be one of "+strings.Join(ExternalProviders, ", "))
+		return
+	}
+
+	item, err := h.svc.UnlinkExternalID(r.Context(), id, claims.UserID, provider)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, item)
+}
+
+// MetadataRefreshes handles GET /api/media/:id/metadata-refreshes.
+func (h *Handler) MetadataRefreshes(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	refreshes, err := h.svc.MetadataRefreshes(r.Context(), id, claims.UserID)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, refreshes)
+}
+
+// writeItemError maps errors from reading and editing items to responses.
+func writeItemError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidExternalID), errors.Is(err, ErrMergeSelf):
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrExternalLookup):
+		httputil.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ErrItemNotFound):
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrItemReadOnly), errors.Is(err, ErrCollectionNotWritable):
+		httputil.WriteError(w, http.StatusForbidden, err.Error())
+	default:
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Duplicates handles GET /api/media/duplicates.
+func (h *Handler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	pairs, err := h.svc.FindDuplicates(r.Context(), claims.UserID, queryFloat(r, "min_score", DefaultDuplicateScore))
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	httputil.WriteJSON(w, http.StatusOK, pairs)
+}
+
+// CheckDuplicates handles POST /api/media/duplicates/check.
+func (h *Handler) CheckDuplicates(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	var req DuplicateCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Title == "" || req.MediaType == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "title and media_type are required")
+		return
+	}
+	if !req.MediaType.Valid() {
+		httputil.WriteError(w, http.StatusBadRequest, "media_type must be movie, music or game")
+		return
+	}
+
+	matches, err := h.svc.CheckDuplicates(r.Context(), claims.UserID, req, queryFloat(r, "min_score", DefaultDuplicateScore))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"is_duplicate": len(matches) > 0,
+		"matches":      matches,
+	})
+}
+
+// Merge handles POST /api/media/:id/merge.
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.SourceID == uuid.Nil {
+		httputil.WriteError(w, http.StatusBadRequest, "source_id is required")
+		return
+	}
+
+	item, err := h.svc.Merge(r.Context(), claims.UserID, id, req.SourceID)
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
 	httputil.WriteJSON(w, http.StatusOK, item)
 }
 
+// BacklogTime handles GET /api/media/backlog-time.
+func (h *Handler) BacklogTime(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	est, err := h.svc.BacklogTime(r.Context(), claims.UserID)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, est)
+}
+
+// Similar handles GET /api/media/:id/similar.
+func (h *Handler) Similar(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	similar, err := h.svc.Similar(r.Context(), id, claims.UserID, queryInt(r, "limit", 10))
+	if err != nil {
+		writeItemError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, similar)
+}
+
+// Pick handles GET /api/media/pick.
+func (h *Handler) Pick(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	q := r.URL.Query()
+
+	f := PickFilter{
+		UserID:     claims.UserID,
+		Statuses:   []Status{StatusOwned, StatusCurrentlyUsing},
+		Count:      queryInt(r, "count", 1),
+		RecentDays: queryInt(r, "recent_days", 14),
+		DryRun:     q.Get("dry_run") == "true",
+	}
+	if t := q.Get("type"); t != "" {
+		mt := MediaType(t)
+		f.MediaType = &mt
+	}
+	if s := q.Get("status"); s != "" {
+		f.Statuses = nil
+		for _, part := range strings.Split(s, ",") {
+			f.Statuses = append(f.Statuses, Status(strings.TrimSpace(part)))
+		}
+	}
+	if g := q.Get("genre"); g != "" {
+		f.Genre = &g
+	}
+	if s := q.Get("seed"); s != "" {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "seed must be an integer")
+			return
+		}
+		f.Seed = &seed
+		// Replaying a seed should not record the same picks again.
+		f.DryRun = q.Get("dry_run") != "false"
+	}
+	if s := q.Get("as_of"); s != "" {
+		asOf, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "as_of must be an RFC 3339 time")
+			return
+		}
+		if asOf.After(time.Now()) {
+			httputil.WriteError(w, http.StatusBadRequest, "as_of must not be in the future")
+			return
+		}
+		f.AsOf = asOf
+	}
+
+	result, err := h.svc.Pick(r.Context(), f)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, result)
+}
+
+const negativeMinutesMessage = "runtime_minutes, duration_minutes and time_to_beat_minutes must not be negative"
+
+// negativeMinutes reports whether any of the set durations is negative.
+func negativeMinutes(minutes ...*int) bool {
+	for _, m := range minutes {
+		if m != nil && *m < 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func queryInt(r *http.Request, key string, defaultVal int) int {
 	v := r.URL.Query().Get(key)
 	if v == "" {
@@ -176,3 +523,15 @@ func queryInt(r *http.Request, key string, defaultVal int) int {
 	}
 	return n
 }
+
+func queryFloat(r *http.Request, key string, defaultVal float64) float64 {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return defaultVal
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return defaultVal
+	}
+	return f
+}
@@ -0,0 +1,42 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteItemError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: not a TMDB id", ErrInvalidExternalID), http.StatusBadRequest},
+		{ErrMergeSelf, http.StatusBadRequest},
+		{fmt.Errorf("%w: tmdb: not found", ErrExternalLookup), http.StatusUnprocessableEntity},
+		{ErrItemNotFound, http.StatusNotFound},
+		{ErrItemReadOnly, http.StatusForbidden},
+		{fmt.Errorf("create item: %w", ErrCollectionNotWritable), http.StatusForbidden},
+		{fmt.Errorf("query item: %w", errors.New("connection reset")), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeItemError(rec, tt.err)
+		if rec.Code != tt.want {
+			t.Errorf("writeItemError(%q) status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestNegativeMinutes(t *testing.T) {
+	zero, positive, negative := 0, 90, -1
+	if negativeMinutes(nil, &zero, &positive) {
+		t.Error("negativeMinutes rejected unset, zero and positive durations")
+	}
+	if !negativeMinutes(&positive, nil, &negative) {
+		t.Error("negativeMinutes accepted a negative duration")
+	}
+}
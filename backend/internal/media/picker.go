@@ -0,0 +1,151 @@
+package media
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPickCount caps how many items a single draw may return.
+const MaxPickCount = 10
+
+// Pick draws items at random from the filtered pool. Items that have waited
+// longer since being added, and items whose genres the user rates highly,
+// are more likely to be drawn. Unless f.DryRun is set the picks are recorded
+// at f.AsOf so they are skipped for f.RecentDays days. The pool is read as of
+// f.AsOf, so replaying a result's seed and as_of draws the same items, with
+// the same recent picks skipped, as long as the items are unchanged.
+func (s *Service) Pick(ctx context.Context, f PickFilter) (*PickResult, error) {
+	if f.Count <= 0 {
+		f.Count = 1
+	}
+	if f.Count > MaxPickCount {
+		f.Count = MaxPickCount
+	}
+	if f.AsOf.IsZero() {
+		f.AsOf = time.Now()
+	}
+	// Whole seconds survive the round trip through the response and the
+	// database unchanged.
+	f.AsOf = f.AsOf.UTC().Truncate(time.Second)
+
+	pool, err := s.repo.ListPickPool(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	genreRatings, err := s.repo.GenreRatings(ctx, f.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := pickSeed(f.Seed)
+	if err != nil {
+		return nil, err
+	}
+	result := &PickResult{Picks: make([]*Pick, 0, f.Count), Seed: seed, AsOf: f.AsOf, PoolSize: len(pool)}
+	if len(pool) == 0 {
+		return result, nil
+	}
+
+	today := f.AsOf.Truncate(24 * time.Hour)
+	candidates := make([]*Pick, len(pool))
+	for i, item := range pool {
+		candidates[i] = weighItem(item, genreRatings, today)
+	}
+
+	rng := mrand.New(mrand.NewSource(seed)) //nolint:gosec // reproducible draws, not security sensitive
+	result.Picks = drawWeighted(rng, candidates, f.Count)
+
+	if !f.DryRun {
+		ids := make([]uuid.UUID, 0, len(result.Picks))
+		for _, p := range result.Picks {
+			ids = append(ids, p.Item.ID)
+		}
+		if err := s.repo.RecordPicks(ctx, f.UserID, ids, f.AsOf); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// weighItem scores an item for the draw. Age is measured in whole days so
+// the weight is stable across a day and seeded draws stay reproducible.
+func weighItem(item *Item, genreRatings map[string]float64, today time.Time) *Pick {
+	var reasons []string
+
+	days := math.Floor(today.Sub(item.CreatedAt.UTC().Truncate(24*time.Hour)).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	ageWeight := 1 + math.Log1p(days/7)
+	if days >= 30 {
+		reasons = append(reasons, fmt.Sprintf("waiting %d days", int(days)))
+	}
+
+	// Affinity is the mean rating of the item's genres, neutral when unknown.
+	affinity, rated := 5.0, 0
+	var sum float64
+	for _, g := range item.Genre {
+		if avg, ok := genreRatings[g]; ok {
+			sum += avg
+			rated++
+		}
+	}
+	if rated > 0 {
+		affinity = sum / float64(rated)
+		if affinity >= 7 {
+			reasons = append(reasons, fmt.Sprintf("you rate similar genres %.1f", affinity))
+		}
+	}
+	genreWeight := 0.5 + affinity/10
+
+	return &Pick{Item: item, Weight: math.Round(ageWeight*genreWeight*1000) / 1000, Reasons: reasons}
+}
+
+// drawWeighted samples n candidates without replacement, proportional to
+// their weights, and records each pick's chance at the time it was drawn.
+func drawWeighted(rng *mrand.Rand, candidates []*Pick, n int) []*Pick {
+	remaining := append([]*Pick(nil), candidates...)
+	picks := make([]*Pick, 0, n)
+
+	for len(picks) < n && len(remaining) > 0 {
+		var total float64
+		for _, c := range remaining {
+			total += c.Weight
+		}
+
+		target := rng.Float64() * total
+		idx := len(remaining) - 1
+		for i, c := range remaining {
+			target -= c.Weight
+			if target < 0 {
+				idx = i
+				break
+			}
+		}
+
+		chosen := remaining[idx]
+		chosen.Chance = math.Round(chosen.Weight/total*1000) / 1000
+		picks = append(picks, chosen)
+		remaining = append(remaining[:idx], remaining[idx+1:]...)
+	}
+	return picks
+}
+
+// pickSeed returns the requested seed or a fresh random one.
+func pickSeed(seed *int64) (int64, error) {
+	if seed != nil {
+		return *seed, nil
+	}
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, fmt.Errorf("generate seed: %w", err)
+	}
+	return int64(binary.LittleEndian.Uint64(buf[:]) >> 1), nil
+}
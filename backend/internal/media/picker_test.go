@@ -0,0 +1,150 @@
+package media
+
+import (
+	"math"
+	mrand "math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWeighItem(t *testing.T) {
+	today := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	ratings := map[string]float64{"RPG": 8, "Puzzle": 4}
+
+	tests := []struct {
+		name    string
+		created time.Time
+		genres  []string
+		weight  float64
+		reasons []string
+	}{
+		{
+			name:    "new item in unrated genre",
+			created: today.Add(15 * time.Hour),
+			genres:  []string{"Horror"},
+			weight:  1,
+		},
+		{
+			name:    "added later the same day",
+			created: today.Add(23 * time.Hour),
+			weight:  1,
+		},
+		{
+			name:    "created in the future",
+			created: today.AddDate(0, 0, 3),
+			weight:  1,
+		},
+		{
+			name:    "waiting a week",
+			created: today.AddDate(0, 0, -7),
+			weight:  1 + math.Log(2),
+		},
+		{
+			name:    "waiting a month in a favourite genre",
+			created: today.AddDate(0, 0, -30).Add(12 * time.Hour),
+			genres:  []string{"RPG", "Horror"},
+			weight:  (1 + math.Log1p(30.0/7)) * 1.3,
+			reasons: []string{"waiting 30 days", "you rate similar genres 8.0"},
+		},
+		{
+			name:    "mixed genre ratings",
+			created: today,
+			genres:  []string{"RPG", "Puzzle"},
+			weight:  1.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &Item{ID: uuid.New(), CreatedAt: tt.created, Genre: tt.genres}
+			p := weighItem(item, ratings, today)
+			if want := math.Round(tt.weight*1000) / 1000; p.Weight != want {
+				t.Errorf("Weight = %v, want %v", p.Weight, want)
+			}
+			if !reflect.DeepEqual(p.Reasons, tt.reasons) {
+				t.Errorf("Reasons = %q, want %q", p.Reasons, tt.reasons)
+			}
+		})
+	}
+}
+
+func pickCandidates(weights ...float64) []*Pick {
+	candidates := make([]*Pick, len(weights))
+	for i, w := range weights {
+		candidates[i] = &Pick{Item: &Item{ID: uuid.New()}, Weight: w}
+	}
+	return candidates
+}
+
+func pickedIDs(picks []*Pick) []uuid.UUID {
+	ids := make([]uuid.UUID, len(picks))
+	for i, p := range picks {
+		ids[i] = p.Item.ID
+	}
+	return ids
+}
+
+func TestDrawWeightedSeeded(t *testing.T) {
+	candidates := pickCandidates(1, 2, 3, 4, 5, 6)
+
+	first := pickedIDs(drawWeighted(mrand.New(mrand.NewSource(42)), candidates, 3)) //nolint:gosec
+	again := pickedIDs(drawWeighted(mrand.New(mrand.NewSource(42)), candidates, 3)) //nolint:gosec
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed drew %v, then %v", first, again)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range first {
+		if seen[id] {
+			t.Errorf("item %s drawn twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestDrawWeightedExhaustsPool(t *testing.T) {
+	picks := drawWeighted(mrand.New(mrand.NewSource(1)), pickCandidates(1, 3), 5) //nolint:gosec
+	if len(picks) != 2 {
+		t.Fatalf("drew %d picks from a pool of 2", len(picks))
+	}
+	if picks[1].Chance != 1 {
+		t.Errorf("last pick chance = %v, want 1", picks[1].Chance)
+	}
+	if w := picks[0].Weight; picks[0].Chance != math.Round(w/4*1000)/1000 {
+		t.Errorf("first pick chance = %v for weight %v of 4", picks[0].Chance, w)
+	}
+}
+
+func TestDrawWeightedFavoursHeavyItems(t *testing.T) {
+	candidates := pickCandidates(9, 1)
+	heavy := 0
+	for seed := int64(0); seed < 1000; seed++ {
+		picks := drawWeighted(mrand.New(mrand.NewSource(seed)), candidates, 1) //nolint:gosec
+		if picks[0] == candidates[0] {
+			heavy++
+		}
+	}
+	if heavy < 850 || heavy > 950 {
+		t.Errorf("item with 90%% of the weight drawn %d times in 1000", heavy)
+	}
+}
+
+func TestPickSeed(t *testing.T) {
+	want := int64(12345)
+	if got, err := pickSeed(&want); err != nil || got != want {
+		t.Errorf("pickSeed(%d) = %d, %v", want, got, err)
+	}
+
+	for i := 0; i < 20; i++ {
+		seed, err := pickSeed(nil)
+		if err != nil {
+			t.Fatalf("pickSeed(nil) error: %v", err)
+		}
+		if seed < 0 {
+			t.Errorf("pickSeed(nil) = %d, want a non-negative seed", seed)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// metadataRetryBase is the delay before retrying an item whose refresh
+// failed temporarily; it doubles with each further failure.
+const metadataRetryBase = time.Hour
+
+// RefreshStaleMetadata re-fetches metadata by external ID for up to limit
+// items whose metadata is older than maxAge, applies it and records what
+// changed. It returns the number of items that changed. Items that fail with
+// a temporary error, such as a rate limit or an unavailable provider, back off
+// from metadataRetryBase up to maxAge; other failures wait a full maxAge.
+func (s *Service) RefreshStaleMetadata(ctx context.Context, maxAge time.Duration, limit int) (int, error) {
+	if s.enricher == nil {
+		return 0, nil
+	}
+
+	items, err := s.repo.StaleMetadata(ctx, time.Now().Add(-maxAge), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, item := range items {
+		provider, externalID, ok := linkedExternalID(item)
+		if !ok {
+			continue
+		}
+
+		enriched, err := s.enricher.EnrichByID(ctx, provider, externalID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return changed, ctx.Err()
+			}
+			slog.Warn("metadata refresh failed", "item_id", item.ID, "provider", provider, "error", err)
+			base := maxAge
+			if temporaryError(err) {
+				base = metadataRetryBase
+			}
+			if err := s.repo.RecordMetadataFailure(ctx, item.ID, base, maxAge); err != nil {
+				return changed, err
+			}
+			continue
+		}
+
+		// The owner can always write their item, including in collections.
+		_, changes, err := s.applyEnrichment(ctx, item, item.UserID, enriched)
+		if err != nil {
+			return changed, err
+		}
+		if err := s.repo.RecordMetadataRefresh(ctx, item.ID, provider, externalID, changes); err != nil {
+			return changed, err
+		}
+		if len(changes) > 0 {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// temporaryError reports whether a metadata lookup failed for a reason that
+// may clear up soon.
+func temporaryError(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && t.Temporary()
+}
+
+// RunMetadataRefresh refreshes stale metadata once at start and then every
+// interval until ctx is cancelled. Lookups go through the providers' shared
+// rate limiters, so a large backlog is worked through at their pace.
+func RunMetadataRefresh(ctx context.Context, svc *Service, maxAge, interval time.Duration, batch int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := svc.RefreshStaleMetadata(ctx, maxAge, batch)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("metadata refresh run failed", "error", err)
+		} else if n > 0 {
+			slog.Info("metadata refresh run", "changed", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,40 +5,97 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Default fuzzy search tuning; see WithFuzzySearch.
+const (
+	DefaultFuzzyThreshold = 0.5
+	DefaultFuzzyWeight    = 0.4
+)
+
+// Default semantic search tuning; see WithSemanticSearch.
+const (
+	DefaultSemanticCandidates = 100
+	DefaultSemanticWeight     = 0.5
+)
+
 // Repository handles database operations for media items.
 type Repository struct {
-	db *pgxpool.Pool
+	db                 *pgxpool.Pool
+	fuzzyThreshold     float64
+	fuzzyWeight        float64
+	semanticCandidates int
+	semanticWeight     float64
+}
+
+// RepositoryOption is a functional option for Repository.
+type RepositoryOption func(*Repository)
+
+// WithFuzzySearch sets the pg_trgm word similarity a query must reach against
+// a title or creator to match despite typos, and the weight (0..1) trigram
+// similarity carries against ts_rank when ranking search results.
+func WithFuzzySearch(threshold, weight float64) RepositoryOption {
+	return func(r *Repository) {
+		r.fuzzyThreshold = threshold
+		r.fuzzyWeight = weight
+	}
+}
+
+// WithSemanticSearch sets how many nearest items a semantic search matches
+// and the weight (0..1) vector similarity carries against the keyword score
+// in hybrid searches.
+func WithSemanticSearch(candidates int, weight float64) RepositoryOption {
+	return func(r *Repository) {
+		r.semanticCandidates = candidates
+		r.semanticWeight = weight
+	}
 }
 
 // NewRepository creates a new media Repository.
-func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *pgxpool.Pool, opts ...RepositoryOption) *Repository {
+	r := &Repository{
+		db:                 db,
+		fuzzyThreshold:     DefaultFuzzyThreshold,
+		fuzzyWeight:        DefaultFuzzyWeight,
+		semanticCandidates: DefaultSemanticCandidates,
+		semanticWeight:     DefaultSemanticWeight,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// scanItem scans a database row into an Item.
-func scanItem(row pgx.Row) (*Item, error) {
+// scanItem scans a database row into an Item. Columns selected after
+// itemColumns are scanned into extra.
+func scanItem(row pgx.Row, extra ...any) (*Item, error) {
 	var item Item
 	var metaJSON []byte
-	var genre []string
+	var genre, tags []string
+	var releaseDate *time.Time
 
-	err := row.Scan(
-		&item.ID, &item.UserID, &item.Title, &item.MediaType,
-		&item.Status, &item.Creator, &genre, &item.ReleaseYear,
-		&item.CoverURL, &item.Notes, &item.Rating,
+	dest := []any{
+		&item.ID, &item.UserID, &item.CollectionID, &item.Title, &item.MediaType,
+		&item.Status, &item.Visibility, &item.Creator, &genre, &tags, &item.ReleaseYear, &releaseDate,
+		&item.CoverURL, &item.Notes, &item.NotesPrivate, &item.Rating,
+		&item.RuntimeMinutes, &item.DurationMinutes, &item.TimeToBeatMinutes,
 		&item.TMDBId, &item.MusicbrainzID, &item.IGDBId,
 		&metaJSON, &item.CreatedAt, &item.UpdatedAt,
-	)
-	if err != nil {
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
 	item.Genre = genre
+	item.Tags = tags
+	if releaseDate != nil {
+		item.ReleaseDate = &Date{Time: *releaseDate}
+	}
 	if metaJSON != nil {
 		if err := json.Unmarshal(metaJSON, &item.Metadata); err != nil {
 			return nil, fmt.Errorf("unmarshal metadata: %w", err)
@@ -50,9 +107,94 @@ func scanItem(row pgx.Row) (*Item, error) {
 	return &item, nil
 }
 
-const itemColumns = `id, user_id, title, media_type, status, creator, genre,
-	release_year, cover_url, notes, rating, tmdb_id, musicbrainz_id, igdb_id,
-	metadata, created_at, updated_at`
+const itemColumns = `id, user_id, collection_id, title, media_type, status, visibility,
+	creator, genre, tags, release_year, release_date, cover_url, notes, notes_private, rating,
+	runtime_minutes, duration_minutes, time_to_beat_minutes,
+	tmdb_id, musicbrainz_id, igdb_id, metadata, created_at, updated_at`
+
+// visibleItems returns a subquery of every item the user bound to parameter n
+// can see: items they created outside any collection plus items in
+// collections they belong to. A creator who leaves a collection loses its
+// items with it. It
+// exposes the media_items columns, but status and rating are the viewer's own;
+// on items someone else created they come from media_item_user_state, and
+// private notes are blanked. The stored search_vector leaves out notes, which
+// have their own notes_vector; notes_readable says whether the viewer may
+// match it, so text queries go through textMatch rather than the vectors.
+func visibleItems(n int) string {
+	return fmt.Sprintf(`(
+		SELECT m.id, m.user_id, m.collection_id, m.title, m.media_type,
+			CASE WHEN m.user_id = $%[1]d THEN m.status ELSE COALESCE(us.status, 'owned') END AS status,
+			m.visibility, m.creator, m.genre, m.tags, m.release_year, m.release_date, m.cover_url,
+			CASE WHEN m.user_id = $%[1]d OR NOT m.notes_private THEN m.notes ELSE '' END AS notes,
+			m.notes_private,
+			CASE WHEN m.user_id = $%[1]d THEN m.rating ELSE us.rating END AS rating,
+			m.runtime_minutes, m.duration_minutes, m.time_to_beat_minutes,
+			m.tmdb_id, m.musicbrainz_id, m.igdb_id, m.metadata,
+			m.search_vector, m.notes_vector,
+			m.user_id = $%[1]d OR NOT m.notes_private AS notes_readable,
+			m.created_at, m.updated_at
+		FROM media_items m
+		LEFT JOIN media_item_user_state us ON us.media_item_id = m.id AND us.user_id = $%[1]d
+		WHERE (m.user_id = $%[1]d AND m.collection_id IS NULL) OR m.collection_id IN (
+			SELECT collection_id FROM collection_members WHERE user_id = $%[1]d
+		)
+	)`, n)
+}
+
+// textMatch returns a predicate matching rows of visibleItems whose
+// search_vector, or notes the viewer may read, match the tsquery expression.
+// Both vectors are stored and indexed, so nothing is parsed per row.
+func textMatch(tsquery string) string {
+	return fmt.Sprintf(`(search_vector @@ %[1]s OR (notes_readable AND notes_vector @@ %[1]s))`, tsquery)
+}
+
+// textVectorExpr is the search_vector of a visibleItems row with the notes
+// the viewer may read, for ranking rows textMatch has already matched.
+const textVectorExpr = `(search_vector || CASE WHEN notes_readable
+		THEN coalesce(notes_vector, ''::tsvector) ELSE ''::tsvector END)`
+
+// writableBy returns a predicate matching rows of the media_items table
+// aliased alias that the user bound to parameter n may edit: items they
+// created outside any collection and items in collections where they are an
+// owner or editor. Within a collection only the role counts.
+func writableBy(alias string, n int) string {
+	return fmt.Sprintf(`((%[1]s.user_id = $%[2]d AND %[1]s.collection_id IS NULL) OR %[1]s.collection_id IN (
+		SELECT collection_id FROM collection_members
+		WHERE user_id = $%[2]d AND role IN ('owner', 'editor')
+	))`, alias, n)
+}
+
+// canWriteCollection reports whether the user may add items to a collection.
+func (r *Repository) canWriteCollection(ctx context.Context, collectionID, userID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM collection_members
+			WHERE collection_id=$1 AND user_id=$2 AND role IN ('owner', 'editor')
+		)`, collectionID, userID,
+	).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check collection access: %w", err)
+	}
+	return ok, nil
+}
+
+// accessError explains why a write to an item matched no rows.
+func (r *Repository) accessError(ctx context.Context, id, userID uuid.UUID) error {
+	var visible bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+visibleItems(2)+` AS media_items WHERE id=$1)`,
+		id, userID,
+	).Scan(&visible)
+	if err != nil {
+		return fmt.Errorf("check item access: %w", err)
+	}
+	if visible {
+		return ErrItemReadOnly
+	}
+	return ErrItemNotFound
+}
 
 // Create inserts a new media item.
 func (r *Repository) Create(ctx context.Context, userID uuid.UUID, req CreateRequest, metaOverride map[string]any) (*Item, error) {
@@ -69,28 +211,47 @@ func (r *Repository) Create(ctx context.Context, userID uuid.UUID, req CreateReq
 	if genre == nil {
 		genre = []string{}
 	}
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	if req.CollectionID != nil {
+		ok, err := r.canWriteCollection(ctx, *req.CollectionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrCollectionNotWritable
+		}
+	}
 
 	row := r.db.QueryRow(ctx, `
-		INSERT INTO media_items (user_id, title, media_type, status, creator, genre,
-			release_year, cover_url, notes, rating, metadata)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		INSERT INTO media_items (user_id, collection_id, title, media_type, status, visibility,
+			creator, genre, tags, release_year, release_date, cover_url, notes, notes_private, rating,
+			runtime_minutes, duration_minutes, time_to_beat_minutes, tmdb_id, musicbrainz_id, igdb_id, metadata)
+		VALUES ($1,$2,$3,$4,$5,
+			COALESCE(NULLIF($6, '')::item_visibility, (SELECT default_visibility FROM users WHERE id=$1)),
+			$7,$8,$9,$10,$11,$12,$13,COALESCE($14::boolean, true),$15,$16,$17,$18,$19,$20,$21,$22)
 		RETURNING `+itemColumns,
-		userID, req.Title, req.MediaType, req.Status, req.Creator, genre,
-		req.ReleaseYear, req.CoverURL, req.Notes, req.Rating, metaJSON,
+		userID, req.CollectionID, req.Title, req.MediaType, req.Status, string(req.Visibility),
+		req.Creator, genre, tags, req.ReleaseYear, dateArg(req.ReleaseDate), req.CoverURL, req.Notes,
+		req.NotesPrivate, req.Rating, req.RuntimeMinutes, req.DurationMinutes, req.TimeToBeatMinutes,
+		req.TMDBId, req.MusicbrainzID, req.IGDBId, metaJSON,
 	)
 	return scanItem(row)
 }
 
-// GetByID fetches a media item by ID.
+// GetByID fetches a media item visible to userID.
 func (r *Repository) GetByID(ctx context.Context, id, userID uuid.UUID) (*Item, error) {
 	row := r.db.QueryRow(ctx,
-		`SELECT `+itemColumns+` FROM media_items WHERE id=$1 AND user_id=$2`,
+		`SELECT `+itemColumns+` FROM `+visibleItems(2)+` AS media_items WHERE id=$1`,
 		id, userID,
 	)
 	item, err := scanItem(row)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("item not found")
+			return nil, ErrItemNotFound
 		}
 		return nil, fmt.Errorf("query item: %w", err)
 	}
@@ -106,10 +267,15 @@ func (r *Repository) List(ctx context.Context, f ListFilter) ([]*Item, int, erro
 		f.Page = 1
 	}
 
-	conditions := []string{"user_id = $1"}
+	conditions := []string{"true"}
 	args := []any{f.UserID}
 	argIdx := 2
 
+	if f.CollectionID != nil {
+		conditions = append(conditions, fmt.Sprintf("collection_id = $%d", argIdx))
+		args = append(args, *f.CollectionID)
+		argIdx++
+	}
 	if f.MediaType != nil {
 		conditions = append(conditions, fmt.Sprintf("media_type = $%d", argIdx))
 		args = append(args, *f.MediaType)
@@ -125,12 +291,46 @@ func (r *Repository) List(ctx context.Context, f ListFilter) ([]*Item, int, erro
 		args = append(args, *f.Genre)
 		argIdx++
 	}
+	if f.Tag != nil {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(tags)", argIdx))
+		args = append(args, *f.Tag)
+		argIdx++
+	}
+	if f.Creator != nil {
+		conditions = append(conditions, fmt.Sprintf(`creator ILIKE '%%' || $%d || '%%'`, argIdx))
+		args = append(args, EscapeLike(*f.Creator))
+		argIdx++
+	}
+	if f.ReleaseYearMin != nil {
+		conditions = append(conditions, fmt.Sprintf("release_year >= $%d", argIdx))
+		args = append(args, *f.ReleaseYearMin)
+		argIdx++
+	}
+	if f.ReleaseYearMax != nil {
+		conditions = append(conditions, fmt.Sprintf("release_year <= $%d", argIdx))
+		args = append(args, *f.ReleaseYearMax)
+		argIdx++
+	}
+	if f.RatingMin != nil {
+		conditions = append(conditions, fmt.Sprintf("rating >= $%d", argIdx))
+		args = append(args, *f.RatingMin)
+		argIdx++
+	}
+	if f.TextQuery != nil {
+		conditions = append(conditions, fmt.Sprintf(`(
+			`+textMatch("plainto_tsquery('english', $%[1]d)")+`
+			OR title ILIKE '%%' || $%[2]d || '%%'
+			OR creator ILIKE '%%' || $%[2]d || '%%'
+		)`, argIdx, argIdx+1))
+		args = append(args, *f.TextQuery, EscapeLike(*f.TextQuery))
+		argIdx += 2
+	}
 
-	where := "WHERE " + strings.Join(conditions, " AND ")
-	countQuery := "SELECT COUNT(*) FROM media_items " + where
+	from := visibleItems(1) + " AS media_items WHERE " + strings.Join(conditions, " AND ")
+	countQuery := "SELECT COUNT(*) FROM " + from
 	query := fmt.Sprintf(
-		`SELECT `+itemColumns+` FROM media_items %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
-		where, argIdx, argIdx+1,
+		`SELECT `+itemColumns+` FROM %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		from, argIdx, argIdx+1,
 	)
 	countArgs := make([]any, argIdx-1)
 	copy(countArgs, args[:argIdx-1])
@@ -162,7 +362,9 @@ func (r *Repository) List(ctx context.Context, f ListFilter) ([]*Item, int, erro
 	return items, total, nil
 }
 
-// Update modifies a media item's fields.
+// Update modifies a media item's fields. Status and rating are personal and
+// are stored per member on shared items; the remaining fields are shared and
+// require edit access.
 func (r *Repository) Update(ctx context.Context, id, userID uuid.UUID, req UpdateRequest) (*Item, error) {
 	sets := []string{}
 	args := []any{}
@@ -173,9 +375,9 @@ func (r *Repository) Update(ctx context.Context, id, userID uuid.UUID, req Updat
 		args = append(args, *req.Title)
 		argIdx++
 	}
-	if req.Status != nil {
-		sets = append(sets, fmt.Sprintf("status=$%d", argIdx))
-		args = append(args, *req.Status)
+	if req.Visibility != nil {
+		sets = append(sets, fmt.Sprintf("visibility=$%d", argIdx))
+		args = append(args, *req.Visibility)
 		argIdx++
 	}
 	if req.Creator != nil {
@@ -188,11 +390,21 @@ func (r *Repository) Update(ctx context.Context, id, userID uuid.UUID, req Updat
 		args = append(args, req.Genre)
 		argIdx++
 	}
+	if req.Tags != nil {
+		sets = append(sets, fmt.Sprintf("tags=$%d", argIdx))
+		args = append(args, req.Tags)
+		argIdx++
+	}
 	if req.ReleaseYear != nil {
 		sets = append(sets, fmt.Sprintf("release_year=$%d", argIdx))
 		args = append(args, *req.ReleaseYear)
 		argIdx++
 	}
+	if req.ReleaseDate != nil {
+		sets = append(sets, fmt.Sprintf("release_date=$%d", argIdx))
+		args = append(args, dateArg(req.ReleaseDate))
+		argIdx++
+	}
 	if req.CoverURL != nil {
 		sets = append(sets, fmt.Sprintf("cover_url=$%d", argIdx))
 		args = append(args, *req.CoverURL)
@@ -203,104 +415,357 @@ func (r *Repository) Update(ctx context.Context, id, userID uuid.UUID, req Updat
 		args = append(args, *req.Notes)
 		argIdx++
 	}
-	if req.Rating != nil {
-		sets = append(sets, fmt.Sprintf("rating=$%d", argIdx))
-		args = append(args, *req.Rating)
+	if req.NotesPrivate != nil {
+		sets = append(sets, fmt.Sprintf("notes_private=$%d", argIdx))
+		args = append(args, *req.NotesPrivate)
+		argIdx++
+	}
+	if req.RuntimeMinutes != nil {
+		sets = append(sets, fmt.Sprintf("runtime_minutes=$%d", argIdx))
+		args = append(args, *req.RuntimeMinutes)
+		argIdx++
+	}
+	if req.DurationMinutes != nil {
+		sets = append(sets, fmt.Sprintf("duration_minutes=$%d", argIdx))
+		args = append(args, *req.DurationMinutes)
+		argIdx++
+	}
+	if req.TimeToBeatMinutes != nil {
+		sets = append(sets, fmt.Sprintf("time_to_beat_minutes=$%d", argIdx))
+		args = append(args, *req.TimeToBeatMinutes)
 		argIdx++
 	}
 
-	if len(sets) == 0 {
-		return r.GetByID(ctx, id, userID)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
+	defer tx.Rollback(ctx) //nolint:errcheck
 
-	args = append(args, id, userID)
-	query := fmt.Sprintf(
-		`UPDATE media_items SET %s WHERE id=$%d AND user_id=$%d RETURNING `+itemColumns,
-		strings.Join(sets, ","), argIdx, argIdx+1,
+	if len(sets) > 0 {
+		args = append(args, id, userID)
+		query := fmt.Sprintf(
+			`UPDATE media_items SET %s WHERE id=$%d AND `+writableBy("media_items", argIdx+1),
+			strings.Join(sets, ","), argIdx,
+		)
+		result, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("update item: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, r.accessError(ctx, id, userID)
+		}
+	}
+
+	if req.Status != nil || req.Rating != nil {
+		if err := setPersonalState(ctx, tx, id, userID, req.Status, req.Rating); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit update: %w", err)
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// setPersonalState stores the user's status and rating for an item. The
+// creator's values live on the item itself; other collection members get a
+// row in media_item_user_state. Nil values are left unchanged.
+func setPersonalState(ctx context.Context, tx pgx.Tx, id, userID uuid.UUID, status *Status, rating *float64) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE media_items SET
+			status = COALESCE($3::media_status, status),
+			rating = COALESCE($4::numeric, rating)
+		WHERE id=$1 AND user_id=$2 AND (collection_id IS NULL OR collection_id IN (
+			SELECT collection_id FROM collection_members WHERE user_id = $2
+		))`,
+		id, userID, status, rating,
 	)
+	if err != nil {
+		return fmt.Errorf("update item: %w", err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
 
-	row := r.db.QueryRow(ctx, query, args...)
-	item, err := scanItem(row)
+	result, err = tx.Exec(ctx, `
+		INSERT INTO media_item_user_state (media_item_id, user_id, status, rating)
+		SELECT m.id, $2, COALESCE($3::media_status, 'owned'), $4::numeric
+		FROM media_items m
+		WHERE m.id = $1 AND m.collection_id IN (
+			SELECT collection_id FROM collection_members WHERE user_id = $2
+		)
+		ON CONFLICT (media_item_id, user_id) DO UPDATE SET
+			status = COALESCE($3::media_status, media_item_user_state.status),
+			rating = COALESCE($4::numeric, media_item_user_state.rating)`,
+		id, userID, status, rating,
+	)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("item not found")
-		}
-		return nil, fmt.Errorf("update item: %w", err)
+		return fmt.Errorf("update personal state: %w", err)
 	}
-	return item, nil
+	if result.RowsAffected() == 0 {
+		return ErrItemNotFound
+	}
+	return nil
 }
 
-// Delete removes a media item.
+// Delete removes a media item the user may edit.
 func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID) error {
 	result, err := r.db.Exec(ctx,
-		"DELETE FROM media_items WHERE id=$1 AND user_id=$2", id, userID,
+		"DELETE FROM media_items WHERE id=$1 AND "+writableBy("media_items", 2), id, userID,
 	)
 	if err != nil {
 		return fmt.Errorf("delete item: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("item not found")
+		return r.accessError(ctx, id, userID)
 	}
 	return nil
 }
 
-// UpdateStatus patches only the status field.
+// UpdateStatus patches only the user's status for an item.
 func (r *Repository) UpdateStatus(ctx context.Context, id, userID uuid.UUID, status Status) (*Item, error) {
-	row := r.db.QueryRow(ctx, `
-		UPDATE media_items SET status=$1 WHERE id=$2 AND user_id=$3 RETURNING `+itemColumns,
-		status, id, userID,
+	return r.Update(ctx, id, userID, UpdateRequest{Status: &status})
+}
+
+// SetCollection moves an item into a shared collection, or out of one when
+// collectionID is nil. The user must be able to edit the item and, when
+// moving it in, be an owner or editor of the target collection.
+func (r *Repository) SetCollection(ctx context.Context, id, userID uuid.UUID, collectionID *uuid.UUID) (*Item, error) {
+	if collectionID != nil {
+		ok, err := r.canWriteCollection(ctx, *collectionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrCollectionNotWritable
+		}
+	}
+
+	result, err := r.db.Exec(ctx,
+		"UPDATE media_items SET collection_id=$3 WHERE id=$1 AND "+writableBy("media_items", 2),
+		id, userID, collectionID,
 	)
-	item, err := scanItem(row)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("item not found")
+		return nil, fmt.Errorf("set collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, r.accessError(ctx, id, userID)
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// externalIDColumns maps each external provider to its media_items column.
+var externalIDColumns = map[string]string{
+	ExternalTMDB:        "tmdb_id",
+	ExternalMusicBrainz: "musicbrainz_id",
+	ExternalIGDB:        "igdb_id",
+}
+
+// SetExternalID links an item the user may edit to an ID at provider, or
+// unlinks it when externalID is nil.
+func (r *Repository) SetExternalID(ctx context.Context, id, userID uuid.UUID, provider string, externalID *string) (*Item, error) {
+	column, ok := externalIDColumns[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown metadata provider: %s", provider)
+	}
+	result, err := r.db.Exec(ctx,
+		`UPDATE media_items SET `+column+` = $3 WHERE id = $1 AND `+writableBy("media_items", 2),
+		id, userID, externalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("set external id: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, r.accessError(ctx, id, userID)
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// MergeMetadata overlays meta onto the metadata of an item the user may
+// edit, keeping keys meta does not set.
+func (r *Repository) MergeMetadata(ctx context.Context, id, userID uuid.UUID, meta map[string]any) error {
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	result, err := r.db.Exec(ctx,
+		`UPDATE media_items SET metadata = metadata || $3 WHERE id = $1 AND `+writableBy("media_items", 2),
+		id, userID, metaJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("merge metadata: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return r.accessError(ctx, id, userID)
+	}
+	return nil
+}
+
+// StaleMetadata returns up to limit items linked to a provider ID whose
+// metadata was last fetched before cutoff, stalest first. Items backing off
+// after a failed refresh are left out until their retry time.
+func (r *Repository) StaleMetadata(ctx context.Context, cutoff time.Time, limit int) ([]*Item, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+itemColumns+` FROM media_items
+		WHERE metadata_refreshed_at < $1
+			AND (tmdb_id IS NOT NULL OR musicbrainz_id IS NOT NULL OR igdb_id IS NOT NULL)
+			AND (metadata_retry_at IS NULL OR metadata_retry_at <= now())
+		ORDER BY metadata_refreshed_at
+		LIMIT $2`,
+		cutoff, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query stale metadata: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
 		}
-		return nil, fmt.Errorf("update status: %w", err)
+		items = append(items, item)
 	}
-	return item, nil
+	return items, rows.Err()
 }
 
-// Search performs a full-text search using tsvector + trigram fallback.
-func (r *Repository) Search(ctx context.Context, userID uuid.UUID, query string, mediaType *MediaType, page, pageSize int) ([]*Item, int, error) {
-	if pageSize <= 0 {
-		pageSize = 20
+// RecordMetadataRefresh marks an item's metadata as fetched now and logs the
+// changes made, if any.
+func (r *Repository) RecordMetadataRefresh(ctx context.Context, id uuid.UUID, provider, externalID string, changes map[string]MetadataChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE media_items
+		SET metadata_refreshed_at = now(), metadata_refresh_failures = 0, metadata_retry_at = NULL
+		WHERE id = $1`, id,
+	); err != nil {
+		return fmt.Errorf("mark metadata refreshed: %w", err)
 	}
-	if page <= 0 {
-		page = 1
+	if len(changes) > 0 {
+		changesJSON, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("marshal changes: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO metadata_refreshes (media_item_id, provider, external_id, changes)
+			VALUES ($1, $2, $3, $4)`,
+			id, provider, externalID, changesJSON,
+		); err != nil {
+			return fmt.Errorf("insert metadata refresh: %w", err)
+		}
 	}
 
-	baseArgs := []any{userID, query}
-	typeFilter := ""
-	if mediaType != nil {
-		typeFilter = " AND media_type=$3"
-		baseArgs = append(baseArgs, *mediaType)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit metadata refresh: %w", err)
 	}
+	return nil
+}
 
-	baseQuery := fmt.Sprintf(`
-		FROM media_items
-		WHERE user_id=$1%s
-		AND (
-			search_vector @@ plainto_tsquery('english', $2)
-			OR title ILIKE '%%' || $2 || '%%'
-			OR creator ILIKE '%%' || $2 || '%%'
-		)`, typeFilter)
+// RecordMetadataFailure schedules the next refresh of an item whose metadata
+// could not be fetched. The delay starts at base and doubles with each
+// consecutive failure, up to maxDelay.
+func (r *Repository) RecordMetadataFailure(ctx context.Context, id uuid.UUID, base, maxDelay time.Duration) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE media_items SET
+			metadata_refresh_failures = metadata_refresh_failures + 1,
+			metadata_retry_at = now() + LEAST(
+				make_interval(secs => $2 * power(2, LEAST(metadata_refresh_failures, 20))),
+				make_interval(secs => $3)
+			)
+		WHERE id = $1`,
+		id, base.Seconds(), maxDelay.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("record metadata failure: %w", err)
+	}
+	return nil
+}
 
-	var total int
-	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) "+baseQuery, baseArgs...).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("count search: %w", err)
+// ListMetadataRefreshes returns the recorded metadata changes of an item,
+// newest first.
+func (r *Repository) ListMetadataRefreshes(ctx context.Context, id uuid.UUID) ([]*MetadataRefresh, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, media_item_id, provider, external_id, changes, refreshed_at
+		FROM metadata_refreshes
+		WHERE media_item_id = $1
+		ORDER BY refreshed_at DESC
+		LIMIT 100`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query metadata refreshes: %w", err)
 	}
+	defer rows.Close()
 
-	limitIdx := len(baseArgs) + 1
-	offsetIdx := limitIdx + 1
-	selectQuery := fmt.Sprintf(
-		`SELECT `+itemColumns+` %s ORDER BY ts_rank(search_vector, plainto_tsquery('english', $2)) DESC LIMIT $%d OFFSET $%d`,
-		baseQuery, limitIdx, offsetIdx,
+	refreshes := []*MetadataRefresh{}
+	for rows.Next() {
+		var (
+			mr          MetadataRefresh
+			changesJSON []byte
+		)
+		if err := rows.Scan(&mr.ID, &mr.MediaItemID, &mr.Provider, &mr.ExternalID, &changesJSON, &mr.RefreshedAt); err != nil {
+			return nil, fmt.Errorf("scan metadata refresh: %w", err)
+		}
+		if err := json.Unmarshal(changesJSON, &mr.Changes); err != nil {
+			return nil, fmt.Errorf("unmarshal changes: %w", err)
+		}
+		refreshes = append(refreshes, &mr)
+	}
+	return refreshes, rows.Err()
+}
+
+// GetAllForUser returns all items visible to a user (used for AI features).
+func (r *Repository) GetAllForUser(ctx context.Context, userID uuid.UUID) ([]*Item, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+itemColumns+` FROM `+visibleItems(1)+` AS media_items ORDER BY created_at DESC`,
+		userID,
 	)
-	allArgs := append(baseArgs, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("query all items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
 
-	rows, err := r.db.Query(ctx, selectQuery, allArgs...)
+// ListPublic returns the owner's items that viewerID may see on the owner's
+// profile, newest first. Anonymous viewers (nil) see public items, accepted
+// followers also see followers-only items, and the owner sees everything. Private notes
+// are always removed, even for the owner, since this is the shared view.
+func (r *Repository) ListPublic(ctx context.Context, ownerID uuid.UUID, viewerID *uuid.UUID, limit int) ([]*Item, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT `+itemColumns+` FROM media_items
+		WHERE user_id = $1 AND (
+			visibility = 'public'
+			OR user_id = $2
+			OR (visibility = 'followers' AND EXISTS (
+				SELECT 1 FROM follows f WHERE f.followee_id = $1 AND f.follower_id = $2 AND f.accepted
+			))
+		)
+		ORDER BY created_at DESC
+		LIMIT $3`,
+		ownerID, viewerID, limit,
+	)
 	if err != nil {
-		return nil, 0, fmt.Errorf("search media: %w", err)
+		return nil, fmt.Errorf("list public items: %w", err)
 	}
 	defer rows.Close()
 
@@ -308,29 +773,424 @@ func (r *Repository) Search(ctx context.Context, userID uuid.UUID, query string,
 	for rows.Next() {
 		item, err := scanItem(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("scan item: %w", err)
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		if item.NotesPrivate {
+			item.Notes = ""
 		}
 		items = append(items, item)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("rows error: %w", err)
+	return items, rows.Err()
+}
+
+// trigramCandidateThreshold is the pg_trgm similarity a title must reach to be
+// considered a duplicate candidate; final scoring happens in the service.
+const trigramCandidateThreshold = "0.3"
+
+// duplicateSignals holds the raw evidence that two items are the same work.
+type duplicateSignals struct {
+	itemID        uuid.UUID
+	matchID       uuid.UUID
+	titleSim      float64
+	creatorSim    *float64
+	itemYear      *int
+	matchYear     *int
+	externalMatch *string
+}
+
+// FindDuplicatePairs returns pairs of same-typed items whose titles are
+// trigram-similar or which share an external provider ID.
+func (r *Repository) FindDuplicatePairs(ctx context.Context, userID uuid.UUID) ([]duplicateSignals, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
+	defer tx.Rollback(ctx) //nolint:errcheck
 
-	return items, total, nil
+	if _, err := tx.Exec(ctx,
+		"SELECT set_config('pg_trgm.similarity_threshold', $1, true)", trigramCandidateThreshold,
+	); err != nil {
+		return nil, fmt.Errorf("set trigram threshold: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT a.id, b.id,
+			similarity(a.title, b.title),
+			CASE WHEN a.creator <> '' AND b.creator <> '' THEN similarity(a.creator, b.creator) END,
+			a.release_year, b.release_year,
+			CASE
+				WHEN a.tmdb_id = b.tmdb_id THEN 'tmdb'
+				WHEN a.musicbrainz_id = b.musicbrainz_id THEN 'musicbrainz'
+				WHEN a.igdb_id = b.igdb_id THEN 'igdb'
+			END
+		FROM `+visibleItems(1)+` a
+		JOIN `+visibleItems(1)+` b
+			ON b.media_type = a.media_type
+			AND a.id < b.id
+			AND (
+				a.title % b.title
+				OR a.tmdb_id = b.tmdb_id
+				OR a.musicbrainz_id = b.musicbrainz_id
+				OR a.igdb_id = b.igdb_id
+			)`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find duplicates: %w", err)
+	}
+	return scanDuplicateSignals(rows)
 }
 
-// GetAllForUser returns all items for a user (used for AI features).
-func (r *Repository) GetAllForUser(ctx context.Context, userID uuid.UUID) ([]*Item, error) {
+// FindDuplicatesOf returns items similar to a prospective item or sharing
+// one of its external IDs.
+func (r *Repository) FindDuplicatesOf(ctx context.Context, userID uuid.UUID, req DuplicateCheckRequest) ([]duplicateSignals, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx,
+		"SELECT set_config('pg_trgm.similarity_threshold', $1, true)", trigramCandidateThreshold,
+	); err != nil {
+		return nil, fmt.Errorf("set trigram threshold: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, id,
+			similarity(title, $3),
+			CASE WHEN creator <> '' AND $4 <> '' THEN similarity(creator, $4) END,
+			release_year, $5::int,
+			CASE
+				WHEN tmdb_id = $6 THEN 'tmdb'
+				WHEN musicbrainz_id = $7 THEN 'musicbrainz'
+				WHEN igdb_id = $8 THEN 'igdb'
+			END
+		FROM `+visibleItems(1)+` AS media_items
+		WHERE media_type = $2 AND (
+			title % $3 OR tmdb_id = $6 OR musicbrainz_id = $7 OR igdb_id = $8
+		)`,
+		userID, req.MediaType, req.Title, req.Creator, req.ReleaseYear,
+		req.TMDBId, req.MusicbrainzID, req.IGDBId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("find duplicates: %w", err)
+	}
+	return scanDuplicateSignals(rows)
+}
+
+func scanDuplicateSignals(rows pgx.Rows) ([]duplicateSignals, error) {
+	defer rows.Close()
+
+	var signals []duplicateSignals
+	for rows.Next() {
+		var s duplicateSignals
+		if err := rows.Scan(
+			&s.itemID, &s.matchID, &s.titleSim, &s.creatorSim,
+			&s.itemYear, &s.matchYear, &s.externalMatch,
+		); err != nil {
+			return nil, fmt.Errorf("scan duplicate: %w", err)
+		}
+		signals = append(signals, s)
+	}
+	return signals, rows.Err()
+}
+
+// GetByIDs fetches the given items visible to userID, keyed by ID.
+func (r *Repository) GetByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]*Item, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT `+itemColumns+` FROM media_items WHERE user_id=$1 ORDER BY created_at DESC`,
+		`SELECT `+itemColumns+` FROM `+visibleItems(1)+` AS media_items WHERE id = ANY($2)`,
+		userID, ids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make(map[uuid.UUID]*Item, len(ids))
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		items[item.ID] = item
+	}
+	return items, rows.Err()
+}
+
+// Merge folds the source item into the target and deletes the source.
+// Notes are concatenated, genres and tags unioned, empty target fields filled from the
+// source, and activity and status history, picks, reviews and members'
+// personal state re-pointed at the target. A source review or personal state is dropped if
+// the target already has one. The user must be able to edit both items.
+func (r *Repository) Merge(ctx context.Context, userID, targetID, sourceID uuid.UUID) (*Item, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	row := tx.QueryRow(ctx, `
+		UPDATE media_items t SET
+			notes = CASE
+				WHEN s.notes = '' THEN t.notes
+				WHEN t.notes = '' THEN s.notes
+				ELSE t.notes || E'\n\n' || s.notes
+			END,
+			genre = ARRAY(
+				SELECT g FROM unnest(t.genre || s.genre) WITH ORDINALITY AS x(g, n)
+				GROUP BY g ORDER BY min(n)
+			),
+			tags = ARRAY(
+				SELECT g FROM unnest(t.tags || s.tags) WITH ORDINALITY AS x(g, n)
+				GROUP BY g ORDER BY min(n)
+			),
+			creator = CASE WHEN t.creator = '' THEN s.creator ELSE t.creator END,
+			cover_url = CASE WHEN t.cover_url = '' THEN s.cover_url ELSE t.cover_url END,
+			release_year = COALESCE(t.release_year, s.release_year),
+			rating = COALESCE(t.rating, s.rating),
+			runtime_minutes = COALESCE(t.runtime_minutes, s.runtime_minutes),
+			duration_minutes = COALESCE(t.duration_minutes, s.duration_minutes),
+			time_to_beat_minutes = COALESCE(t.time_to_beat_minutes, s.time_to_beat_minutes),
+			tmdb_id = COALESCE(t.tmdb_id, s.tmdb_id),
+			musicbrainz_id = COALESCE(t.musicbrainz_id, s.musicbrainz_id),
+			igdb_id = COALESCE(t.igdb_id, s.igdb_id),
+			metadata = s.metadata || t.metadata,
+			created_at = LEAST(t.created_at, s.created_at)
+		FROM media_items s
+		WHERE t.id = $1 AND s.id = $2 AND `+writableBy("t", 3)+` AND `+writableBy("s", 3)+`
+		RETURNING t.id`,
+		targetID, sourceID, userID,
+	)
+	var mergedID uuid.UUID
+	if err := row.Scan(&mergedID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, r.mergeAccessError(ctx, userID, targetID, sourceID)
+		}
+		return nil, fmt.Errorf("merge items: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE activity_events SET media_item_id=$1 WHERE media_item_id=$2", targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("move activity: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE media_status_history SET media_item_id=$1 WHERE media_item_id=$2", targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("move status history: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE media_picks SET media_item_id=$1 WHERE media_item_id=$2", targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("move picks: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE reviews SET media_item_id=$1
+		WHERE media_item_id=$2 AND NOT EXISTS (
+			SELECT 1 FROM reviews t WHERE t.media_item_id=$1 AND t.user_id=reviews.user_id
+		)`, targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("move reviews: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE media_item_user_state SET media_item_id=$1
+		WHERE media_item_id=$2 AND NOT EXISTS (
+			SELECT 1 FROM media_item_user_state t
+			WHERE t.media_item_id=$1 AND t.user_id=media_item_user_state.user_id
+		)`, targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("move personal state: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM media_items WHERE id=$1", sourceID); err != nil {
+		return nil, fmt.Errorf("delete merged item: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+	return r.GetByID(ctx, targetID, userID)
+}
+
+// mergeAccessError explains why a merge matched no rows: the first of the
+// items the user may not edit is either missing or read-only to them.
+func (r *Repository) mergeAccessError(ctx context.Context, userID uuid.UUID, ids ...uuid.UUID) error {
+	for _, id := range ids {
+		var writable bool
+		err := r.db.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM media_items WHERE id=$1 AND `+writableBy("media_items", 2)+`)`,
+			id, userID,
+		).Scan(&writable)
+		if err != nil {
+			return fmt.Errorf("check item access: %w", err)
+		}
+		if !writable {
+			return r.accessError(ctx, id, userID)
+		}
+	}
+	return ErrItemNotFound
+}
+
+// ListUpcoming returns wishlist items releasing on or after from, soonest first.
+func (r *Repository) ListUpcoming(ctx context.Context, userID uuid.UUID, from time.Time, limit int) ([]*Item, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT `+itemColumns+` FROM `+visibleItems(1)+` AS media_items
+		WHERE status='wishlist' AND release_date >= $2::date
+		ORDER BY release_date, title
+		LIMIT $3`,
+		userID, from, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query upcoming: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListReleaseRefreshCandidates returns wishlist items the user may edit whose
+// release date is unknown or still in the future, least recently checked
+// first; items never checked come before all others.
+func (r *Repository) ListReleaseRefreshCandidates(ctx context.Context, userID uuid.UUID, limit int) ([]*Item, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+itemColumns+` FROM `+visibleItems(1)+` AS media_items
+		WHERE status='wishlist' AND `+writableBy("media_items", 1)+`
+		AND (release_date IS NULL OR release_date >= CURRENT_DATE)
+		ORDER BY (SELECT m.release_checked_at FROM media_items m WHERE m.id = media_items.id) NULLS FIRST, updated_at
+		LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query refresh candidates: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// MarkReleaseChecked records that an item's release date was just looked up,
+// whether or not the lookup succeeded.
+func (r *Repository) MarkReleaseChecked(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE media_items SET release_checked_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("mark release checked: %w", err)
+	}
+	return nil
+}
+
+// keywordsExpr is the stemmed lexemes of an item's provider overview and
+// any metadata keywords, used as keyword features for similarity.
+const keywordsExpr = `tsvector_to_array(to_tsvector('english',
+		coalesce(metadata->>'overview', '') || ' ' || coalesce((
+			SELECT string_agg(k, ' ') FROM jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(metadata->'keywords') = 'array' THEN metadata->'keywords' ELSE '[]' END
+			) AS k
+		), '')
+	))`
+
+// featureRow is the subset of an item compared when ranking similar items.
+type featureRow struct {
+	ID          uuid.UUID
+	Title       string
+	Creator     string
+	Genre       []string
+	Tags        []string
+	ReleaseYear *int
+	Keywords    []string
+}
+
+// ListFeatures returns the similarity features of every item visible to the
+// user. Only the compared columns are read; callers load full items for the
+// few they return.
+func (r *Repository) ListFeatures(ctx context.Context, userID uuid.UUID) ([]featureRow, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, title, creator, genre, tags, release_year, `+keywordsExpr+`
+		FROM `+visibleItems(1)+` AS media_items`,
 		userID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("query all items: %w", err)
+		return nil, fmt.Errorf("query item features: %w", err)
 	}
 	defer rows.Close()
 
-	var items []*Item
+	var features []featureRow
+	for rows.Next() {
+		var f featureRow
+		if err := rows.Scan(&f.ID, &f.Title, &f.Creator, &f.Genre, &f.Tags, &f.ReleaseYear, &f.Keywords); err != nil {
+			return nil, fmt.Errorf("scan item features: %w", err)
+		}
+		features = append(features, f)
+	}
+	return features, rows.Err()
+}
+
+// ListPickPool returns the items eligible for a random pick, excluding those
+// picked within the f.RecentDays days before f.AsOf. Picks recorded at or
+// after f.AsOf are ignored and items are ordered by ID, so replaying a seeded
+// draw with the same f.AsOf reads the same pool.
+func (r *Repository) ListPickPool(ctx context.Context, f PickFilter) ([]*Item, error) {
+	conditions := []string{"true"}
+	args := []any{f.UserID}
+	argIdx := 2
+
+	if f.MediaType != nil {
+		conditions = append(conditions, fmt.Sprintf("media_type = $%d", argIdx))
+		args = append(args, *f.MediaType)
+		argIdx++
+	}
+	if len(f.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("status::text = ANY($%d)", argIdx))
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = string(s)
+		}
+		args = append(args, statuses)
+		argIdx++
+	}
+	if f.Genre != nil {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(genre)", argIdx))
+		args = append(args, *f.Genre)
+		argIdx++
+	}
+	if f.RecentDays > 0 {
+		conditions = append(conditions, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM media_picks p
+			WHERE p.user_id = $1 AND p.media_item_id = media_items.id
+			AND p.picked_at > $%[1]d::timestamptz - make_interval(days => $%[2]d)
+			AND p.picked_at < $%[1]d::timestamptz
+		)`, argIdx, argIdx+1))
+		args = append(args, f.AsOf, f.RecentDays)
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT `+itemColumns+` FROM `+visibleItems(1)+` AS media_items WHERE `+strings.Join(conditions, " AND ")+` ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query pick pool: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
 	for rows.Next() {
 		item, err := scanItem(rows)
 		if err != nil {
@@ -340,3 +1200,79 @@ func (r *Repository) GetAllForUser(ctx context.Context, userID uuid.UUID) ([]*It
 	}
 	return items, rows.Err()
 }
+
+// GenreRatings returns the user's average rating per genre over rated items.
+func (r *Repository) GenreRatings(ctx context.Context, userID uuid.UUID) (map[string]float64, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT g, avg(rating)::float8
+		FROM `+visibleItems(1)+` AS media_items, unnest(genre) AS g
+		WHERE rating IS NOT NULL
+		GROUP BY g`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query genre ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[string]float64)
+	for rows.Next() {
+		var genre string
+		var avg float64
+		if err := rows.Scan(&genre, &avg); err != nil {
+			return nil, fmt.Errorf("scan genre rating: %w", err)
+		}
+		ratings[genre] = avg
+	}
+	return ratings, rows.Err()
+}
+
+// RecordPicks stores that the given items were suggested to the user at
+// pickedAt.
+func (r *Repository) RecordPicks(ctx context.Context, userID uuid.UUID, itemIDs []uuid.UUID, pickedAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO media_picks (user_id, media_item_id, picked_at)
+		SELECT $1, unnest($2::uuid[]), $3`,
+		userID, itemIDs, pickedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("record picks: %w", err)
+	}
+	return nil
+}
+
+// BacklogBuckets sums estimated minutes of unfinished items by status and type.
+// Each item contributes the estimate that matches its media type.
+func (r *Repository) BacklogBuckets(ctx context.Context, userID uuid.UUID) ([]BacklogBucket, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH est AS (
+			SELECT status, media_type,
+				CASE media_type
+					WHEN 'movie' THEN runtime_minutes
+					WHEN 'music' THEN duration_minutes
+					WHEN 'game' THEN time_to_beat_minutes
+				END AS minutes
+			FROM `+visibleItems(1)+` AS media_items
+			WHERE status <> 'completed'
+		)
+		SELECT status, media_type, COUNT(*), COUNT(minutes), COALESCE(SUM(minutes), 0)
+		FROM est
+		GROUP BY status, media_type
+		ORDER BY status, media_type`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query backlog: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make([]BacklogBucket, 0)
+	for rows.Next() {
+		var b BacklogBucket
+		if err := rows.Scan(&b.Status, &b.MediaType, &b.Items, &b.EstimatedItems, &b.Minutes); err != nil {
+			return nil, fmt.Errorf("scan backlog bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
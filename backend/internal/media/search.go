@@ -0,0 +1,469 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxGenreFacets caps how many genre values a search reports.
+const maxGenreFacets = 25
+
+// ts_headline marks matches and separates fragments with these control
+// characters. They pass through HTML escaping unchanged, so fragments are
+// escaped first and the sentinels swapped for the caller's markers afterwards.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+	fragmentDelim  = "\x1f"
+)
+
+// Headline options for short fields, highlighted in full, and for long text
+// fields, reduced to a few fragments around the matches.
+const (
+	shortHeadlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", HighlightAll=true`
+	longHeadlineOptions  = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", ` +
+		`MaxFragments=3, MaxWords=20, MinWords=8, FragmentDelimiter="` + fragmentDelim + `"`
+)
+
+// highlightFields are the fields that receive headlines, in select order.
+var highlightFields = []string{"title", "creator", "notes", "overview"}
+
+// Facet dimensions of a SearchFilter.
+const (
+	facetMediaType = "media_type"
+	facetStatus    = "status"
+	facetGenre     = "genre"
+	facetDecade    = "decade"
+	facetRating    = "rating"
+)
+
+// ratingBucketExpr maps an item's rating to its facet bucket.
+const ratingBucketExpr = `CASE
+		WHEN rating IS NULL THEN 'unrated'
+		WHEN rating < 2 THEN '0-2'
+		WHEN rating < 4 THEN '2-4'
+		WHEN rating < 6 THEN '4-6'
+		WHEN rating < 8 THEN '6-8'
+		ELSE '8-10'
+	END`
+
+// decadeExpr maps an item's release year to the first year of its decade.
+const decadeExpr = `(release_year / 10 * 10)`
+
+// ratingBucketOrder is the display order of the rating facet.
+var ratingBucketOrder = []string{
+	RatingBucket8to10, RatingBucket6to8, RatingBucket4to6,
+	RatingBucket2to4, RatingBucket0to2, RatingBucketUnrated,
+}
+
+// fuzzyScoreExpr is the trigram similarity of the query ($2) to the closest
+// word run in an item's title or creator.
+const fuzzyScoreExpr = `GREATEST(word_similarity($2, title), word_similarity($2, creator))`
+
+// fuzzyMatchExpr matches items whose fuzzyScoreExpr reaches
+// pg_trgm.word_similarity_threshold. Unlike a comparison of the score, the
+// <% operator is served by the title and creator trigram indexes.
+const fuzzyMatchExpr = `($2 <% title OR $2 <% creator)`
+
+// setFuzzyThreshold sets pg_trgm.word_similarity_threshold for the rest of
+// tx, which is the threshold fuzzyMatchExpr applies.
+func setFuzzyThreshold(ctx context.Context, tx pgx.Tx, threshold float64) error {
+	if _, err := tx.Exec(ctx,
+		"SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)",
+		strconv.FormatFloat(threshold, 'f', -1, 64),
+	); err != nil {
+		return fmt.Errorf("set trigram threshold: %w", err)
+	}
+	return nil
+}
+
+// suggestSources maps each suggestion kind to the expression yielding its
+// values and the FROM tail that unnests array columns.
+var suggestSources = map[SuggestionKind]struct{ expr, tail string }{
+	SuggestTitle:   {"title", ""},
+	SuggestCreator: {"creator", ""},
+	SuggestGenre:   {"g", ", unnest(genre) AS g"},
+	SuggestTag:     {"g", ", unnest(tags) AS g"},
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes LIKE wildcards in s so user input matches literally.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Suggest returns up to limit distinct values of one kind from the user's
+// items that complete q. Values starting with q rank first, then values with
+// a later word starting with q, then trigram matches, so "witchr" still
+// suggests "The Witcher". Title and creator lookups are served by the trigram
+// indexes; the fuzzy match uses pg_trgm.word_similarity_threshold.
+func (r *Repository) Suggest(ctx context.Context, userID uuid.UUID, kind SuggestionKind, q string, limit int) ([]Suggestion, error) {
+	src, ok := suggestSources[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown suggestion kind: %s", kind)
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	query := fmt.Sprintf(`
+		SELECT value, COUNT(*), (array_agg(id ORDER BY updated_at DESC))[1],
+			MAX(CASE
+				WHEN value ILIKE $2 THEN 1
+				WHEN value ILIKE '%% ' || $2 THEN 0.9
+				ELSE word_similarity($3, value)
+			END) AS score
+		FROM (
+			SELECT media_items.id, media_items.updated_at, %s AS value
+			FROM %s AS media_items%s
+		) AS terms
+		WHERE value <> '' AND (value ILIKE '%%' || $2 OR $3 <%% value)
+		GROUP BY value
+		ORDER BY score DESC, COUNT(*) DESC, value
+		LIMIT $4`,
+		src.expr, visibleItems(1), src.tail,
+	)
+	rows, err := r.db.Query(ctx, query, userID, EscapeLike(q)+"%", q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("suggest %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]Suggestion, 0)
+	for rows.Next() {
+		var s Suggestion
+		var itemID uuid.UUID
+		if err := rows.Scan(&s.Value, &s.Count, &itemID, &s.Score); err != nil {
+			return nil, fmt.Errorf("scan %s suggestion: %w", kind, err)
+		}
+		if kind == SuggestTitle {
+			s.ItemID = &itemID
+		}
+		s.Score = math.Round(s.Score*1000) / 1000
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+// semanticScoreExpr is the cosine similarity of an item's vector to the
+// query embedding ($3) under the query's model ($4), or 0 without a vector.
+const semanticScoreExpr = `COALESCE(1 - (
+		SELECT e.embedding <=> $3::vector FROM media_embeddings e
+		WHERE e.media_item_id = media_items.id AND e.model = $4
+	), 0)`
+
+// semantic reports whether the search uses the query embedding.
+func (f SearchFilter) semantic() bool {
+	return (f.Mode == SearchSemantic || f.Mode == SearchHybrid) && f.Query != "" && len(f.Embedding) > 0
+}
+
+// searchConditions builds the WHERE clause for a search, applying every facet
+// selection except the one named by skip. $1 is the user and $2 the query;
+// semantic searches add the query embedding as $3 and its model as $4. The
+// fuzzy match needs setFuzzyThreshold on the querying transaction. The semantic candidates are the nearest items that
+// pass the other filters, so filtering never empties a full candidate list.
+func (r *Repository) searchConditions(f SearchFilter, skip string) (string, []any) {
+	keywordMatch := `(
+			` + textMatch("plainto_tsquery('english', $2)") + `
+			OR title ILIKE '%' || $2 || '%'
+			OR creator ILIKE '%' || $2 || '%'
+			OR ` + fuzzyMatchExpr + `
+		)`
+
+	filters := []string{"true"}
+	args := []any{f.UserID, f.Query}
+	argIdx := 3
+	if f.semantic() {
+		args = append(args, f.Embedding.String(), f.EmbeddingModel)
+		argIdx += 2
+	}
+
+	if f.Where != "" {
+		filters = append(filters, "("+rebind(f.Where, argIdx)+")")
+		args = append(args, f.WhereArgs...)
+		argIdx += len(f.WhereArgs)
+	}
+
+	if len(f.MediaTypes) > 0 && skip != facetMediaType {
+		types := make([]string, len(f.MediaTypes))
+		for i, t := range f.MediaTypes {
+			types[i] = string(t)
+		}
+		filters = append(filters, fmt.Sprintf("media_type::text = ANY($%d)", argIdx))
+		args = append(args, types)
+		argIdx++
+	}
+	if len(f.Statuses) > 0 && skip != facetStatus {
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = string(s)
+		}
+		filters = append(filters, fmt.Sprintf("status::text = ANY($%d)", argIdx))
+		args = append(args, statuses)
+		argIdx++
+	}
+	if len(f.Genres) > 0 && skip != facetGenre {
+		filters = append(filters, fmt.Sprintf("genre && $%d::text[]", argIdx))
+		args = append(args, f.Genres)
+		argIdx++
+	}
+	if len(f.Decades) > 0 && skip != facetDecade {
+		filters = append(filters, fmt.Sprintf(decadeExpr+" = ANY($%d::int[])", argIdx))
+		args = append(args, f.Decades)
+		argIdx++
+	}
+	if len(f.RatingBuckets) > 0 && skip != facetRating {
+		filters = append(filters, fmt.Sprintf(ratingBucketExpr+" = ANY($%d)", argIdx))
+		args = append(args, f.RatingBuckets)
+	}
+
+	where := strings.Join(filters, " AND ")
+	nearest := fmt.Sprintf(`id IN (
+			SELECT media_items.id FROM %s AS media_items
+			JOIN (SELECT media_item_id, embedding FROM media_embeddings WHERE model = $4) AS e
+				ON e.media_item_id = media_items.id
+			WHERE %s
+			ORDER BY e.embedding <=> $3::vector
+			LIMIT %d
+		)`, visibleItems(1), where, r.semanticCandidates)
+
+	switch {
+	case f.Query == "":
+	case !f.semantic():
+		where += " AND " + keywordMatch
+	case f.Mode == SearchSemantic:
+		where += " AND " + nearest
+	default:
+		where += " AND (" + keywordMatch + " OR " + nearest + ")"
+	}
+
+	return "FROM " + visibleItems(1) + " AS media_items WHERE " + where, args
+}
+
+// rebind replaces each ? placeholder in a predicate with a numbered
+// parameter, starting at $start.
+func rebind(predicate string, start int) string {
+	var b strings.Builder
+	n := start
+	for _, c := range predicate {
+		if c == '?' {
+			b.WriteString("$" + strconv.Itoa(n))
+			n++
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Search performs a full-text search using tsvector + trigram fallback and
+// returns one page of matches, with highlighted fragments for each matched
+// field, along with facet counts. Keyword matches are ranked by a blend of
+// ts_rank and trigram similarity, so misspelled titles and creators still
+// surface. Semantic searches match the items nearest the query embedding and
+// rank by cosine similarity; hybrid searches match either way and blend the
+// keyword and vector scores.
+func (r *Repository) Search(ctx context.Context, f SearchFilter) (*SearchResult, error) {
+	if f.PageSize <= 0 {
+		f.PageSize = 20
+	}
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Highlight.Start == "" && f.Highlight.Stop == "" {
+		f.Highlight = DefaultHighlightMarkers
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := setFuzzyThreshold(ctx, tx, r.fuzzyThreshold); err != nil {
+		return nil, err
+	}
+
+	from, args := r.searchConditions(f, "")
+
+	result := &SearchResult{Items: make([]*Hit, 0)}
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) "+from, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("count search: %w", err)
+	}
+
+	semanticScore, semanticWeight := "0", 0.0
+	if f.semantic() {
+		semanticScore, semanticWeight = semanticScoreExpr, r.semanticWeight
+		if f.Mode == SearchSemantic {
+			semanticWeight = 1
+		}
+	}
+
+	n := len(args)
+	query := fmt.Sprintf(`
+		SELECT `+itemColumns+`,
+			ts_headline('english', title, plainto_tsquery('english', $2), $%[2]d),
+			ts_headline('english', creator, plainto_tsquery('english', $2), $%[2]d),
+			ts_headline('english', notes, plainto_tsquery('english', $2), $%[3]d),
+			ts_headline('english', coalesce(metadata->>'overview', ''), plainto_tsquery('english', $2), $%[3]d),
+			score
+		FROM (
+			SELECT *,
+				(1 - $%[7]d::float8) * (
+					(1 - $%[4]d::float8) * ts_rank(`+textVectorExpr+`, plainto_tsquery('english', $2))
+					+ $%[4]d::float8 * `+fuzzyScoreExpr+`
+				) + $%[7]d::float8 * %[8]s AS score
+			%[1]s
+		) AS matches
+		ORDER BY score DESC, title
+		LIMIT $%[5]d OFFSET $%[6]d`,
+		from, n+1, n+2, n+3, n+4, n+5, n+6, semanticScore,
+	)
+	args = append(args, shortHeadlineOptions, longHeadlineOptions, r.fuzzyWeight, f.PageSize, (f.Page-1)*f.PageSize, semanticWeight)
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search media: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		headlines := make([]string, len(highlightFields))
+		extra := make([]any, len(headlines))
+		for i := range headlines {
+			extra[i] = &headlines[i]
+		}
+		var score float64
+		item, err := scanItem(rows, append(extra, &score)...)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		hit := &Hit{Item: item, Score: math.Round(score*1000) / 1000}
+		for i, field := range highlightFields {
+			if fragments := highlightFragments(headlines[i], f.Highlight); len(fragments) > 0 {
+				if hit.Highlights == nil {
+					hit.Highlights = make(map[string][]string)
+				}
+				hit.Highlights[field] = fragments
+			}
+		}
+		result.Items = append(result.Items, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := r.searchFacets(ctx, tx, f, &result.Facets); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// highlightFragments splits a ts_headline result into fragments that contain
+// a match, HTML-escapes them and swaps the sentinels for the given markers.
+func highlightFragments(headline string, m HighlightMarkers) []string {
+	if !strings.Contains(headline, highlightStart) {
+		return nil
+	}
+	markers := strings.NewReplacer(highlightStart, m.Start, highlightStop, m.Stop)
+
+	var fragments []string
+	for _, frag := range strings.Split(headline, fragmentDelim) {
+		if !strings.Contains(frag, highlightStart) {
+			continue
+		}
+		fragments = append(fragments, markers.Replace(html.EscapeString(strings.TrimSpace(frag))))
+	}
+	return fragments
+}
+
+// searchFacets counts matches per value of each facet dimension.
+func (r *Repository) searchFacets(ctx context.Context, tx pgx.Tx, f SearchFilter, facets *Facets) error {
+	selected := func(values ...string) map[string]bool {
+		m := make(map[string]bool, len(values))
+		for _, v := range values {
+			m[v] = true
+		}
+		return m
+	}
+	types := make([]string, len(f.MediaTypes))
+	for i, t := range f.MediaTypes {
+		types[i] = string(t)
+	}
+	statuses := make([]string, len(f.Statuses))
+	for i, s := range f.Statuses {
+		statuses[i] = string(s)
+	}
+	decades := make([]string, len(f.Decades))
+	for i, d := range f.Decades {
+		decades[i] = strconv.Itoa(d)
+	}
+
+	var err error
+	if facets.MediaType, err = r.facetCounts(ctx, tx, f, facetMediaType,
+		"media_type::text", "", "ORDER BY 1", selected(types...)); err != nil {
+		return err
+	}
+	if facets.Status, err = r.facetCounts(ctx, tx, f, facetStatus,
+		"status::text", "", "ORDER BY 1", selected(statuses...)); err != nil {
+		return err
+	}
+	if facets.Genre, err = r.facetCounts(ctx, tx, f, facetGenre,
+		"g", ", unnest(genre) AS g", fmt.Sprintf("ORDER BY 2 DESC, 1 LIMIT %d", maxGenreFacets),
+		selected(f.Genres...)); err != nil {
+		return err
+	}
+	if facets.Decade, err = r.facetCounts(ctx, tx, f, facetDecade,
+		decadeExpr+"::text", " WHERE release_year IS NOT NULL", "ORDER BY 1 DESC", selected(decades...)); err != nil {
+		return err
+	}
+	if facets.Rating, err = r.facetCounts(ctx, tx, f, facetRating,
+		ratingBucketExpr, "", "", selected(f.RatingBuckets...)); err != nil {
+		return err
+	}
+	sortRatingFacets(facets.Rating)
+	return nil
+}
+
+// facetCounts groups the matches for one dimension by expr. tail follows the
+// matched rows in the FROM clause, either to join them or to filter them.
+func (r *Repository) facetCounts(ctx context.Context, tx pgx.Tx, f SearchFilter, dim, expr, tail, order string, selected map[string]bool) ([]FacetCount, error) {
+	from, args := r.searchConditions(f, dim)
+	query := fmt.Sprintf(
+		"SELECT %s, COUNT(*) FROM (SELECT * %s) AS matches%s GROUP BY 1 %s",
+		expr, from, tail, order,
+	)
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count %s facet: %w", dim, err)
+	}
+	defer rows.Close()
+
+	counts := make([]FacetCount, 0)
+	for rows.Next() {
+		var fc FacetCount
+		if err := rows.Scan(&fc.Value, &fc.Count); err != nil {
+			return nil, fmt.Errorf("scan %s facet: %w", dim, err)
+		}
+		fc.Selected = selected[fc.Value]
+		counts = append(counts, fc)
+	}
+	return counts, rows.Err()
+}
+
+// sortRatingFacets orders rating buckets from highest to unrated.
+func sortRatingFacets(counts []FacetCount) {
+	rank := make(map[string]int, len(ratingBucketOrder))
+	for i, b := range ratingBucketOrder {
+		rank[b] = i
+	}
+	sort.Slice(counts, func(i, j int) bool { return rank[counts[i].Value] < rank[counts[j].Value] })
+}
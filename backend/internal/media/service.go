@@ -2,8 +2,14 @@ package media
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
@@ -12,8 +18,33 @@ import (
 // MetadataEnricher enriches media metadata by external provider.
 type MetadataEnricher interface {
 	Enrich(ctx context.Context, title string, mediaType MediaType, releaseYear *int) (map[string]any, error)
+	// EnrichByID fetches metadata for an ID at a named provider.
+	EnrichByID(ctx context.Context, provider, externalID string) (map[string]any, error)
 }
 
+// ErrExternalLookup is returned when metadata for an external ID cannot be
+// fetched, for example because the provider does not know the ID.
+var ErrExternalLookup = errors.New("external metadata lookup failed")
+
+// ErrInvalidExternalID is returned when an external ID is malformed or its
+// provider does not serve the item's media type.
+var ErrInvalidExternalID = errors.New("invalid external id")
+
+// ErrItemNotFound is returned for items that do not exist or that the user
+// cannot see.
+var ErrItemNotFound = errors.New("item not found")
+
+// ErrItemReadOnly is returned when the user can see an item but may not edit
+// it.
+var ErrItemReadOnly = errors.New("item is read-only")
+
+// ErrMergeSelf is returned when an item is merged into itself.
+var ErrMergeSelf = errors.New("cannot merge an item into itself")
+
+// ErrCollectionNotWritable is returned when an item is added to a collection
+// the user does not belong to or may only view.
+var ErrCollectionNotWritable = errors.New("collection not found or read-only")
+
 // Service orchestrates media operations with optional enrichment.
 type Service struct {
 	repo     *Repository
@@ -27,6 +58,10 @@ func NewService(repo *Repository, enricher MetadataEnricher) *Service {
 
 // Create adds a new media item, optionally enriching with external metadata.
 func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Item, error) {
+	if err := req.normalizeExternalIDs(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExternalID, err)
+	}
+
 	var metaOverride map[string]any
 
 	if req.EnrichMetadata && s.enricher != nil {
@@ -49,6 +84,10 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateReques
 
 		if enriched != nil {
 			metaOverride = enriched
+			if source, ok := enriched["source"].(string); ok {
+				externalID, _ := enriched["external_id"].(string)
+				req.setExternalID(source, externalID)
+			}
 			if req.CoverURL == "" {
 				if url, ok := enriched["cover_url"].(string); ok {
 					req.CoverURL = url
@@ -64,9 +103,26 @@ func (s *Service) Create(ctx context.Context, userID uuid.UUID, req CreateReques
 					req.Genre = genres
 				}
 			}
+			if req.ReleaseDate == nil {
+				req.ReleaseDate = enrichedDate(enriched)
+			}
+			if req.RuntimeMinutes == nil {
+				req.RuntimeMinutes = enrichedMinutes(enriched, "runtime_minutes")
+			}
+			if req.DurationMinutes == nil {
+				req.DurationMinutes = enrichedMinutes(enriched, "duration_minutes")
+			}
+			if req.TimeToBeatMinutes == nil {
+				req.TimeToBeatMinutes = enrichedMinutes(enriched, "time_to_beat_minutes")
+			}
 		}
 	}
 
+	if req.ReleaseDate != nil && req.ReleaseYear == nil {
+		yr := req.ReleaseDate.Year()
+		req.ReleaseYear = &yr
+	}
+
 	item, err := s.repo.Create(ctx, userID, req, metaOverride)
 	if err != nil {
 		return nil, fmt.Errorf("create item: %w", err)
@@ -99,7 +155,409 @@ func (s *Service) UpdateStatus(ctx context.Context, id, userID uuid.UUID, status
 	return s.repo.UpdateStatus(ctx, id, userID, status)
 }
 
+// SetCollection moves an item into or out of a shared collection.
+func (s *Service) SetCollection(ctx context.Context, id, userID uuid.UUID, collectionID *uuid.UUID) (*Item, error) {
+	return s.repo.SetCollection(ctx, id, userID, collectionID)
+}
+
 // GetAllForUser returns all items for AI features.
 func (s *Service) GetAllForUser(ctx context.Context, userID uuid.UUID) ([]*Item, error) {
 	return s.repo.GetAllForUser(ctx, userID)
 }
+
+// maxReleaseRefresh bounds how many provider lookups one refresh performs.
+const maxReleaseRefresh = 25
+
+// ListUpcoming returns wishlist items with a release date from today onward.
+func (s *Service) ListUpcoming(ctx context.Context, userID uuid.UUID, limit int) ([]*Item, error) {
+	return s.repo.ListUpcoming(ctx, userID, time.Now().UTC(), limit)
+}
+
+// RefreshReleaseDates re-fetches release dates from metadata providers for
+// wishlist items that have not been released yet, returning the number of
+// items whose date changed. Items linked to an external ID are fetched by
+// that ID rather than searched for by title. Every lookup, failed or not,
+// marks the item checked so later refreshes move on to other items.
+func (s *Service) RefreshReleaseDates(ctx context.Context, userID uuid.UUID) (int, error) {
+	if s.enricher == nil {
+		return 0, fmt.Errorf("metadata enrichment not configured")
+	}
+
+	items, err := s.repo.ListReleaseRefreshCandidates(ctx, userID, maxReleaseRefresh)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, item := range items {
+		enriched, err := s.enrichItem(ctx, item)
+		if err != nil && ctx.Err() != nil {
+			return updated, ctx.Err()
+		}
+		if markErr := s.repo.MarkReleaseChecked(ctx, item.ID); markErr != nil {
+			return updated, markErr
+		}
+		if err != nil {
+			slog.Warn("release date refresh failed", "item_id", item.ID, "error", err)
+			continue
+		}
+		date := enrichedDate(enriched)
+		if date == nil || (item.ReleaseDate != nil && item.ReleaseDate.Equal(date.Time)) {
+			continue
+		}
+
+		yr := date.Year()
+		if _, err := s.repo.Update(ctx, item.ID, userID, UpdateRequest{ReleaseDate: date, ReleaseYear: &yr}); err != nil {
+			return updated, fmt.Errorf("update release date: %w", err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// nativeProviders is the external provider preferred for each media type.
+var nativeProviders = map[MediaType]string{
+	MediaTypeMovie: ExternalTMDB,
+	MediaTypeMusic: ExternalMusicBrainz,
+	MediaTypeGame:  ExternalIGDB,
+}
+
+// linkedExternalID returns the provider and ID to refresh an item from,
+// preferring the provider native to its media type.
+func linkedExternalID(item *Item) (string, string, bool) {
+	providers := append([]string{nativeProviders[item.MediaType]}, ExternalProviders...)
+	for _, p := range providers {
+		if id := item.ExternalID(p); id != nil && *id != "" {
+			return p, *id, true
+		}
+	}
+	return "", "", false
+}
+
+// enrichItem fetches fresh metadata for an item, by its linked external ID
+// when it has one and by title otherwise.
+func (s *Service) enrichItem(ctx context.Context, item *Item) (map[string]any, error) {
+	if provider, externalID, ok := linkedExternalID(item); ok {
+		return s.enricher.EnrichByID(ctx, provider, externalID)
+	}
+	return s.enricher.Enrich(ctx, item.Title, item.MediaType, item.ReleaseYear)
+}
+
+// LinkExternalID links an item to an ID at a metadata provider and refreshes
+// its metadata from that ID. The ID must be well-formed and the provider must
+// serve the item's media type. Nothing is stored unless the provider knows the
+// ID; without an enricher the ID is stored unchecked.
+func (s *Service) LinkExternalID(ctx context.Context, id, userID uuid.UUID, provider, externalID string) (*Item, error) {
+	item, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if externalID, err = NormalizeExternalID(provider, item.MediaType, externalID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExternalID, err)
+	}
+
+	var enriched map[string]any
+	if s.enricher != nil {
+		if enriched, err = s.enricher.EnrichByID(ctx, provider, externalID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExternalLookup, err)
+		}
+	}
+
+	if item, err = s.repo.SetExternalID(ctx, id, userID, provider, &externalID); err != nil {
+		return nil, err
+	}
+	if enriched == nil {
+		return item, nil
+	}
+	item, changes, err := s.applyEnrichment(ctx, item, userID, enriched)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RecordMetadataRefresh(ctx, id, provider, externalID, changes); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UnlinkExternalID removes an item's ID at a metadata provider. Metadata
+// fetched from it is kept.
+func (s *Service) UnlinkExternalID(ctx context.Context, id, userID uuid.UUID, provider string) (*Item, error) {
+	return s.repo.SetExternalID(ctx, id, userID, provider, nil)
+}
+
+// MetadataRefreshes lists the recorded metadata changes of an item the user
+// can see, newest first.
+func (s *Service) MetadataRefreshes(ctx context.Context, id, userID uuid.UUID) ([]*MetadataRefresh, error) {
+	if _, err := s.repo.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMetadataRefreshes(ctx, id)
+}
+
+// applyEnrichment brings an item in line with fresh provider metadata and
+// returns the updated item and the fields that changed.
+func (s *Service) applyEnrichment(ctx context.Context, item *Item, userID uuid.UUID, enriched map[string]any) (*Item, map[string]MetadataChange, error) {
+	req, changes := enrichmentUpdate(item, enriched)
+	if err := s.repo.MergeMetadata(ctx, item.ID, userID, enriched); err != nil {
+		return nil, nil, err
+	}
+	updated, err := s.repo.Update(ctx, item.ID, userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, changes, nil
+}
+
+// enrichmentUpdate builds the update that applies provider metadata to an
+// item and reports the fields it changes. The cover, genres, release date and
+// play times are only replaced while they are empty or still hold what a
+// provider set last time, as recorded in the item's metadata, so user edits
+// are kept. The creator is only filled in when empty, and the title is never
+// touched.
+func enrichmentUpdate(item *Item, enriched map[string]any) (UpdateRequest, map[string]MetadataChange) {
+	var req UpdateRequest
+	changes := map[string]MetadataChange{}
+	prev := item.Metadata
+
+	if url, ok := enriched["cover_url"].(string); ok && url != "" && url != item.CoverURL &&
+		(item.CoverURL == "" || item.CoverURL == prev["cover_url"]) {
+		req.CoverURL = &url
+		changes["cover_url"] = MetadataChange{Old: item.CoverURL, New: url}
+	}
+	if creator, ok := enriched["creator"].(string); ok && creator != "" && item.Creator == "" {
+		req.Creator = &creator
+		changes["creator"] = MetadataChange{Old: item.Creator, New: creator}
+	}
+	if genres, ok := enriched["genres"].([]string); ok && len(genres) > 0 && !slices.Equal(genres, item.Genre) &&
+		(len(item.Genre) == 0 || slices.Equal(item.Genre, storedStrings(prev["genres"]))) {
+		req.Genre = genres
+		changes["genre"] = MetadataChange{Old: item.Genre, New: genres}
+	}
+	if date := enrichedDate(enriched); date != nil && (item.ReleaseDate == nil || !item.ReleaseDate.Equal(date.Time)) {
+		if old := enrichedDate(prev); item.ReleaseDate == nil || (old != nil && old.Equal(item.ReleaseDate.Time)) {
+			yr := date.Year()
+			req.ReleaseDate, req.ReleaseYear = date, &yr
+			changes["release_date"] = MetadataChange{Old: item.ReleaseDate, New: date}
+		}
+	}
+	for _, f := range []struct {
+		key string
+		cur *int
+		dst **int
+	}{
+		{"runtime_minutes", item.RuntimeMinutes, &req.RuntimeMinutes},
+		{"duration_minutes", item.DurationMinutes, &req.DurationMinutes},
+		{"time_to_beat_minutes", item.TimeToBeatMinutes, &req.TimeToBeatMinutes},
+	} {
+		m := enrichedMinutes(enriched, f.key)
+		if m == nil || (f.cur != nil && *f.cur == *m) {
+			continue
+		}
+		if old := storedMinutes(prev, f.key); f.cur == nil || (old != nil && *old == *f.cur) {
+			*f.dst = m
+			changes[f.key] = MetadataChange{Old: f.cur, New: *m}
+		}
+	}
+	if overview, ok := enriched["overview"].(string); ok && overview != "" && overview != prev["overview"] {
+		changes["overview"] = MetadataChange{Old: prev["overview"], New: overview}
+	}
+	return req, changes
+}
+
+// storedStrings converts a string list decoded from stored JSON metadata.
+func storedStrings(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// storedMinutes extracts a positive minute count from stored JSON metadata,
+// where numbers decode as float64.
+func storedMinutes(meta map[string]any, key string) *int {
+	if f, ok := meta[key].(float64); ok && f > 0 {
+		m := int(f)
+		return &m
+	}
+	return nil
+}
+
+// enrichedDate extracts the release date from an enrichment map, if present.
+func enrichedDate(enriched map[string]any) *Date {
+	ds, ok := enriched["release_date"].(string)
+	if !ok || ds == "" {
+		return nil
+	}
+	d, err := ParseDate(ds)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+// enrichedMinutes extracts a positive minute count from an enrichment map.
+func enrichedMinutes(enriched map[string]any, key string) *int {
+	if m, ok := enriched[key].(int); ok && m > 0 {
+		return &m
+	}
+	return nil
+}
+
+// BacklogTime sums the estimated remaining time of all unfinished items.
+func (s *Service) BacklogTime(ctx context.Context, userID uuid.UUID) (*BacklogEstimate, error) {
+	buckets, err := s.repo.BacklogBuckets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	est := &BacklogEstimate{
+		ByStatus: make(map[Status]int),
+		ByType:   make(map[MediaType]int),
+		Buckets:  buckets,
+	}
+	for _, b := range buckets {
+		est.TotalMinutes += b.Minutes
+		est.UnestimatedItems += b.Items - b.EstimatedItems
+		est.ByStatus[b.Status] += b.Minutes
+		est.ByType[b.MediaType] += b.Minutes
+	}
+	est.TotalHours = float64(est.TotalMinutes*10/60) / 10
+	return est, nil
+}
+
+// Duplicate scoring weights; an external ID match is always a certain duplicate.
+const (
+	titleWeight   = 0.6
+	creatorWeight = 0.25
+	yearWeight    = 0.15
+
+	// DefaultDuplicateScore is the minimum score reported as a likely duplicate.
+	DefaultDuplicateScore = 0.6
+)
+
+// FindDuplicates scans the user's collection for likely duplicate pairs using
+// trigram title and creator similarity, release year and external IDs.
+func (s *Service) FindDuplicates(ctx context.Context, userID uuid.UUID, minScore float64) ([]*DuplicatePair, error) {
+	signals, err := s.repo.FindDuplicatePairs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(signals)*2)
+	for _, sig := range signals {
+		ids = append(ids, sig.itemID, sig.matchID)
+	}
+	items, err := s.repo.GetByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]*DuplicatePair, 0)
+	for _, sig := range signals {
+		item, match := items[sig.itemID], items[sig.matchID]
+		if item == nil || match == nil {
+			continue
+		}
+		score, reasons := scoreDuplicate(sig, item.Title, match.Title)
+		if score < minScore {
+			continue
+		}
+		pairs = append(pairs, &DuplicatePair{Item: item, Match: match, Score: score, Reasons: reasons})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Score > pairs[j].Score })
+	return pairs, nil
+}
+
+// CheckDuplicates returns existing items that likely duplicate a prospective item.
+func (s *Service) CheckDuplicates(ctx context.Context, userID uuid.UUID, req DuplicateCheckRequest, minScore float64) ([]*DuplicateMatch, error) {
+	signals, err := s.repo.FindDuplicatesOf(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(signals))
+	for _, sig := range signals {
+		ids = append(ids, sig.itemID)
+	}
+	items, err := s.repo.GetByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*DuplicateMatch, 0)
+	for _, sig := range signals {
+		item := items[sig.itemID]
+		if item == nil {
+			continue
+		}
+		score, reasons := scoreDuplicate(sig, item.Title, req.Title)
+		if score < minScore {
+			continue
+		}
+		matches = append(matches, &DuplicateMatch{Item: item, Score: score, Reasons: reasons})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches, nil
+}
+
+// Merge folds sourceID into targetID, keeping the target.
+func (s *Service) Merge(ctx context.Context, userID, targetID, sourceID uuid.UUID) (*Item, error) {
+	if targetID == sourceID {
+		return nil, ErrMergeSelf
+	}
+	return s.repo.Merge(ctx, userID, targetID, sourceID)
+}
+
+// scoreDuplicate blends the similarity signals into a 0..1 score.
+// Missing creators or years count as neutral rather than as a mismatch.
+func scoreDuplicate(sig duplicateSignals, titleA, titleB string) (float64, []string) {
+	if sig.externalMatch != nil {
+		return 1, []string{"same " + *sig.externalMatch + " id"}
+	}
+
+	var reasons []string
+	titleScore := sig.titleSim
+	if normalizeTitle(titleA) == normalizeTitle(titleB) {
+		titleScore = 1
+		reasons = append(reasons, "identical title")
+	} else {
+		reasons = append(reasons, fmt.Sprintf("similar title (%.2f)", sig.titleSim))
+	}
+
+	creatorScore := 0.5
+	if sig.creatorSim != nil {
+		creatorScore = *sig.creatorSim
+		if creatorScore >= 0.5 {
+			reasons = append(reasons, fmt.Sprintf("similar creator (%.2f)", creatorScore))
+		}
+	}
+
+	yearScore := 0.5
+	if sig.itemYear != nil && sig.matchYear != nil {
+		diff := *sig.itemYear - *sig.matchYear
+		if diff >= -1 && diff <= 1 {
+			yearScore = 1
+			reasons = append(reasons, "same release year")
+		} else {
+			yearScore = 0
+		}
+	}
+
+	score := titleWeight*titleScore + creatorWeight*creatorScore + yearWeight*yearScore
+	return float64(int(score*1000+0.5)) / 1000, reasons
+}
+
+// normalizeTitle lowercases a title and strips punctuation and a leading article.
+func normalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(fields) > 1 && (fields[0] == "the" || fields[0] == "a" || fields[0] == "an") {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
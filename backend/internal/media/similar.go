@@ -0,0 +1,223 @@
+package media
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxSimilarCount caps how many similar items a single request may return.
+const MaxSimilarCount = 50
+
+// maxKeywordMatches caps the shared keywords listed in an explanation.
+const maxKeywordMatches = 5
+
+// Feature weights for item similarity. Set features are compared by Jaccard
+// overlap, keywords by overlap weighted by how rare each keyword is in the
+// collection.
+const (
+	similarGenreWeight    = 3
+	similarCreatorWeight  = 2.5
+	similarTagWeight      = 2
+	similarKeywordsWeight = 2
+	similarDecadeWeight   = 1
+
+	totalSimilarityWeight = similarGenreWeight + similarCreatorWeight + similarTagWeight + similarKeywordsWeight + similarDecadeWeight
+)
+
+// similarityFeatures are the normalized features of one item.
+type similarityFeatures struct {
+	genres      map[string]string
+	tags        map[string]string
+	creator     string
+	creatorName string
+	decade      int
+	keywords    map[string]bool
+}
+
+// Similar ranks the user's other items by what they share with the item:
+// genres, creator, decade, tags and metadata keywords. Each result explains
+// which features matched. Items sharing nothing are left out.
+func (s *Service) Similar(ctx context.Context, id, userID uuid.UUID, limit int) ([]*SimilarItem, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, MaxSimilarCount)
+
+	rows, err := s.repo.ListFeatures(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]similarityFeatures, len(rows))
+	docFreq := make(map[string]int)
+	target := -1
+	for i, row := range rows {
+		features[i] = itemFeatures(row)
+		for kw := range features[i].keywords {
+			docFreq[kw]++
+		}
+		if row.ID == id {
+			target = i
+		}
+	}
+	if target < 0 {
+		return nil, ErrItemNotFound
+	}
+
+	idf := func(kw string) float64 {
+		return math.Log(1 + float64(len(rows))/float64(docFreq[kw]))
+	}
+
+	type candidate struct {
+		row *featureRow
+		sim *SimilarItem
+	}
+	candidates := make([]candidate, 0)
+	for i := range rows {
+		if i == target {
+			continue
+		}
+		if sim := compareFeatures(features[target], features[i], idf); sim != nil {
+			candidates = append(candidates, candidate{row: &rows[i], sim: sim})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].sim.Score != candidates[j].sim.Score {
+			return candidates[i].sim.Score > candidates[j].sim.Score
+		}
+		return candidates[i].row.Title < candidates[j].row.Title
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ids := make([]uuid.UUID, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.row.ID
+	}
+	items, err := s.repo.GetByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SimilarItem, 0, len(candidates))
+	for _, c := range candidates {
+		// Items deleted since the features were read are skipped.
+		if item, ok := items[c.row.ID]; ok {
+			c.sim.Item = item
+			results = append(results, c.sim)
+		}
+	}
+	return results, nil
+}
+
+// itemFeatures normalizes an item's features for comparison. Genre and tag
+// maps are keyed by lowercase value and keep the original spelling.
+func itemFeatures(row featureRow) similarityFeatures {
+	f := similarityFeatures{
+		genres:      make(map[string]string, len(row.Genre)),
+		tags:        make(map[string]string, len(row.Tags)),
+		creator:     strings.ToLower(strings.TrimSpace(row.Creator)),
+		creatorName: strings.TrimSpace(row.Creator),
+		keywords:    make(map[string]bool, len(row.Keywords)),
+	}
+	for _, g := range row.Genre {
+		f.genres[strings.ToLower(g)] = g
+	}
+	for _, t := range row.Tags {
+		f.tags[strings.ToLower(t)] = t
+	}
+	if row.ReleaseYear != nil {
+		f.decade = *row.ReleaseYear / 10 * 10
+	}
+	for _, kw := range row.Keywords {
+		f.keywords[kw] = true
+	}
+	return f
+}
+
+// compareFeatures scores a candidate against the target, returning nil if
+// they share no feature. The score is the weighted mean of the per-feature
+// similarities, so it falls between 0 and 1.
+func compareFeatures(target, cand similarityFeatures, idf func(string) float64) *SimilarItem {
+	sim := &SimilarItem{}
+	var total float64
+	add := func(feature string, weight, score float64, values []string) {
+		if score <= 0 {
+			return
+		}
+		contribution := weight * score / totalSimilarityWeight
+		total += contribution
+		sim.Matches = append(sim.Matches, FeatureMatch{
+			Feature: feature,
+			Values:  values,
+			Score:   math.Round(contribution*1000) / 1000,
+		})
+	}
+
+	if score, shared := jaccard(target.genres, cand.genres); score > 0 {
+		add("genre", similarGenreWeight, score, shared)
+	}
+	if target.creator != "" && target.creator == cand.creator {
+		add("creator", similarCreatorWeight, 1, []string{target.creatorName})
+	}
+	if score, shared := jaccard(target.tags, cand.tags); score > 0 {
+		add("tag", similarTagWeight, score, shared)
+	}
+
+	var shared []string
+	var sharedWeight, unionWeight float64
+	for kw := range target.keywords {
+		unionWeight += idf(kw)
+		if cand.keywords[kw] {
+			shared = append(shared, kw)
+			sharedWeight += idf(kw)
+		}
+	}
+	for kw := range cand.keywords {
+		if !target.keywords[kw] {
+			unionWeight += idf(kw)
+		}
+	}
+	if len(shared) > 0 {
+		sort.Slice(shared, func(i, j int) bool {
+			if idf(shared[i]) != idf(shared[j]) {
+				return idf(shared[i]) > idf(shared[j])
+			}
+			return shared[i] < shared[j]
+		})
+		add("keywords", similarKeywordsWeight, sharedWeight/unionWeight, shared[:min(len(shared), maxKeywordMatches)])
+	}
+
+	if target.decade != 0 && target.decade == cand.decade {
+		add("decade", similarDecadeWeight, 1, []string{strconv.Itoa(target.decade) + "s"})
+	}
+
+	if len(sim.Matches) == 0 {
+		return nil
+	}
+	// The total is rounded once, so a full match scores 1.
+	sim.Score = math.Round(total*1000) / 1000
+	return sim
+}
+
+// jaccard returns the Jaccard overlap of two feature sets and the shared
+// values in the target's spelling, sorted.
+func jaccard(a, b map[string]string) (float64, []string) {
+	var shared []string
+	for k, v := range a {
+		if _, ok := b[k]; ok {
+			shared = append(shared, v)
+		}
+	}
+	if len(shared) == 0 {
+		return 0, nil
+	}
+	sort.Strings(shared)
+	return float64(len(shared)) / float64(len(a)+len(b)-len(shared)), shared
+}
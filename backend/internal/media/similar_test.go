@@ -0,0 +1,114 @@
+package media
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   map[string]string
+		score  float64
+		shared []string
+	}{
+		{"both empty", nil, nil, 0, nil},
+		{"disjoint", map[string]string{"rpg": "RPG"}, map[string]string{"puzzle": "Puzzle"}, 0, nil},
+		{"identical", map[string]string{"rpg": "RPG"}, map[string]string{"rpg": "rpg"}, 1, []string{"RPG"}},
+		{
+			name:   "partial overlap in target spelling",
+			a:      map[string]string{"rpg": "RPG", "action": "Action", "horror": "Horror"},
+			b:      map[string]string{"rpg": "rpg", "action": "action", "puzzle": "puzzle"},
+			score:  0.5,
+			shared: []string{"Action", "RPG"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, shared := jaccard(tt.a, tt.b)
+			if score != tt.score || !reflect.DeepEqual(shared, tt.shared) {
+				t.Errorf("jaccard = %v, %q, want %v, %q", score, shared, tt.score, tt.shared)
+			}
+		})
+	}
+}
+
+func TestCompareFeatures(t *testing.T) {
+	year := func(y int) *int { return &y }
+	idf := func(string) float64 { return 1 }
+
+	target := itemFeatures(featureRow{
+		Creator:     " Hideo Kojima ",
+		Genre:       []string{"Action", "Stealth"},
+		Tags:        []string{"favourite"},
+		ReleaseYear: year(1998),
+		Keywords:    []string{"soldier", "nuclear"},
+	})
+
+	t.Run("shares nothing", func(t *testing.T) {
+		cand := itemFeatures(featureRow{Creator: "Someone Else", Genre: []string{"Puzzle"}, ReleaseYear: year(2010)})
+		if sim := compareFeatures(target, cand, idf); sim != nil {
+			t.Errorf("compareFeatures = %+v, want nil", sim)
+		}
+	})
+
+	t.Run("shares everything", func(t *testing.T) {
+		cand := itemFeatures(featureRow{
+			Creator:     "hideo kojima",
+			Genre:       []string{"stealth", "action"},
+			Tags:        []string{"Favourite"},
+			ReleaseYear: year(1991),
+			Keywords:    []string{"nuclear", "soldier"},
+		})
+		sim := compareFeatures(target, cand, idf)
+		if sim == nil {
+			t.Fatal("compareFeatures = nil")
+		}
+		if sim.Score != 1 {
+			t.Errorf("Score = %v, want 1", sim.Score)
+		}
+		want := []FeatureMatch{
+			{Feature: "genre", Values: []string{"Action", "Stealth"}, Score: 0.286},
+			{Feature: "creator", Values: []string{"Hideo Kojima"}, Score: 0.238},
+			{Feature: "tag", Values: []string{"favourite"}, Score: 0.19},
+			{Feature: "keywords", Values: []string{"nuclear", "soldier"}, Score: 0.19},
+			{Feature: "decade", Values: []string{"1990s"}, Score: 0.095},
+		}
+		if !reflect.DeepEqual(sim.Matches, want) {
+			t.Errorf("Matches = %+v, want %+v", sim.Matches, want)
+		}
+	})
+
+	t.Run("rare keywords weigh more", func(t *testing.T) {
+		rarity := map[string]float64{"soldier": 1, "nuclear": 3}
+		cand := itemFeatures(featureRow{Keywords: []string{"soldier", "nuclear", "island"}})
+		sim := compareFeatures(target, cand, func(kw string) float64 {
+			if w, ok := rarity[kw]; ok {
+				return w
+			}
+			return 1
+		})
+		if sim == nil || len(sim.Matches) != 1 {
+			t.Fatalf("compareFeatures = %+v, want one keyword match", sim)
+		}
+		m := sim.Matches[0]
+		if !reflect.DeepEqual(m.Values, []string{"nuclear", "soldier"}) {
+			t.Errorf("keywords = %q, want the rarer keyword first", m.Values)
+		}
+		// 4 of 5 keyword weight is shared.
+		if want := math.Round(similarKeywordsWeight*0.8/totalSimilarityWeight*1000) / 1000; m.Score != want {
+			t.Errorf("keyword score = %v, want %v", m.Score, want)
+		}
+	})
+
+	t.Run("blank creators do not match", func(t *testing.T) {
+		a := itemFeatures(featureRow{Creator: " ", Genre: []string{"Jazz"}})
+		b := itemFeatures(featureRow{Creator: "", Genre: []string{"Jazz"}})
+		sim := compareFeatures(a, b, idf)
+		if sim == nil || len(sim.Matches) != 1 || sim.Matches[0].Feature != "genre" {
+			t.Errorf("compareFeatures = %+v, want only the genre to match", sim)
+		}
+	})
+}
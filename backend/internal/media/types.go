@@ -2,9 +2,14 @@
 package media
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/embedding"
 )
 
 // MediaType represents the type of media.
@@ -16,6 +21,11 @@ const (
 	MediaTypeGame  MediaType = "game"
 )
 
+// Valid reports whether t is a known media type.
+func (t MediaType) Valid() bool {
+	return t == MediaTypeMovie || t == MediaTypeMusic || t == MediaTypeGame
+}
+
 // Status represents the user's ownership/usage status for an item.
 type Status string
 
@@ -26,51 +36,253 @@ const (
 	StatusCompleted      Status = "completed"
 )
 
+// Visibility controls who can see an item on the owner's public profile.
+type Visibility string
+
+const (
+	VisibilityPublic    Visibility = "public"
+	VisibilityFollowers Visibility = "followers"
+	VisibilityPrivate   Visibility = "private"
+)
+
+// Valid reports whether v is a known visibility.
+func (v Visibility) Valid() bool {
+	return v == VisibilityPublic || v == VisibilityFollowers || v == VisibilityPrivate
+}
+
+// dateLayout is the wire and storage format for Date.
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day, encoded as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("parse date %q: %w", s, err)
+	}
+	return Date{Time: t}, nil
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+// MarshalJSON encodes the date as a YYYY-MM-DD string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a YYYY-MM-DD string.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// dateArg converts an optional Date into a database argument.
+func dateArg(d *Date) *time.Time {
+	if d == nil {
+		return nil
+	}
+	return &d.Time
+}
+
 // Item represents a media item in the collection.
 type Item struct {
-	ID            uuid.UUID      `json:"id"`
-	UserID        uuid.UUID      `json:"user_id"`
-	Title         string         `json:"title"`
-	MediaType     MediaType      `json:"media_type"`
-	Status        Status         `json:"status"`
-	Creator       string         `json:"creator"`
-	Genre         []string       `json:"genre"`
-	ReleaseYear   *int           `json:"release_year,omitempty"`
-	CoverURL      string         `json:"cover_url"`
-	Notes         string         `json:"notes"`
-	Rating        *float64       `json:"rating,omitempty"`
-	TMDBId        *string        `json:"tmdb_id,omitempty"`
-	MusicbrainzID *string        `json:"musicbrainz_id,omitempty"`
-	IGDBId        *string        `json:"igdb_id,omitempty"`
-	Metadata      map[string]any `json:"metadata"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
+	ID                uuid.UUID      `json:"id"`
+	UserID            uuid.UUID      `json:"user_id"`
+	CollectionID      *uuid.UUID     `json:"collection_id,omitempty"`
+	Title             string         `json:"title"`
+	MediaType         MediaType      `json:"media_type"`
+	Status            Status         `json:"status"`
+	Visibility        Visibility     `json:"visibility"`
+	Creator           string         `json:"creator"`
+	Genre             []string       `json:"genre"`
+	Tags              []string       `json:"tags"`
+	ReleaseYear       *int           `json:"release_year,omitempty"`
+	ReleaseDate       *Date          `json:"release_date,omitempty"`
+	CoverURL          string         `json:"cover_url"`
+	Notes             string         `json:"notes"`
+	NotesPrivate      bool           `json:"notes_private"`
+	Rating            *float64       `json:"rating,omitempty"`
+	RuntimeMinutes    *int           `json:"runtime_minutes,omitempty"`
+	DurationMinutes   *int           `json:"duration_minutes,omitempty"`
+	TimeToBeatMinutes *int           `json:"time_to_beat_minutes,omitempty"`
+	TMDBId            *string        `json:"tmdb_id,omitempty"`
+	MusicbrainzID     *string        `json:"musicbrainz_id,omitempty"`
+	IGDBId            *string        `json:"igdb_id,omitempty"`
+	Metadata          map[string]any `json:"metadata"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// External metadata providers whose IDs are stored on items.
+const (
+	ExternalTMDB        = "tmdb"
+	ExternalMusicBrainz = "musicbrainz"
+	ExternalIGDB        = "igdb"
+)
+
+// ExternalProviders lists the providers with an ID column on media_items.
+var ExternalProviders = []string{ExternalTMDB, ExternalMusicBrainz, ExternalIGDB}
+
+// ValidExternalProvider reports whether items store IDs for provider.
+func ValidExternalProvider(provider string) bool {
+	for _, p := range ExternalProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// externalProviderTypes is the media type each external provider's IDs
+// refer to.
+var externalProviderTypes = map[string]MediaType{
+	ExternalTMDB:        MediaTypeMovie,
+	ExternalMusicBrainz: MediaTypeMusic,
+	ExternalIGDB:        MediaTypeGame,
+}
+
+// NormalizeExternalID checks that id is a valid ID at provider for an item of
+// mediaType and returns it in canonical form. TMDB and IGDB IDs are positive
+// integers and MusicBrainz IDs are UUIDs.
+func NormalizeExternalID(provider string, mediaType MediaType, id string) (string, error) {
+	want, ok := externalProviderTypes[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown metadata provider: %s", provider)
+	}
+	if mediaType != want {
+		return "", fmt.Errorf("%s ids cannot be linked to %s items", provider, mediaType)
+	}
+
+	id = strings.TrimSpace(id)
+	switch provider {
+	case ExternalMusicBrainz:
+		u, err := uuid.Parse(id)
+		if err != nil {
+			return "", fmt.Errorf("%s id must be a UUID", provider)
+		}
+		return u.String(), nil
+	default:
+		n, err := strconv.Atoi(id)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("%s id must be a positive integer", provider)
+		}
+		return strconv.Itoa(n), nil
+	}
+}
+
+// ExternalID returns the item's ID at provider, or nil if it is not linked.
+func (i *Item) ExternalID(provider string) *string {
+	switch provider {
+	case ExternalTMDB:
+		return i.TMDBId
+	case ExternalMusicBrainz:
+		return i.MusicbrainzID
+	case ExternalIGDB:
+		return i.IGDBId
+	default:
+		return nil
+	}
 }
 
 // CreateRequest is the payload for creating a new media item.
 type CreateRequest struct {
-	Title          string    `json:"title"`
-	MediaType      MediaType `json:"media_type"`
-	Status         Status    `json:"status"`
-	Creator        string    `json:"creator"`
-	Genre          []string  `json:"genre"`
-	ReleaseYear    *int      `json:"release_year,omitempty"`
-	CoverURL       string    `json:"cover_url"`
-	Notes          string    `json:"notes"`
-	Rating         *float64  `json:"rating,omitempty"`
-	EnrichMetadata bool      `json:"enrich_metadata"`
+	Title             string     `json:"title"`
+	MediaType         MediaType  `json:"media_type"`
+	Status            Status     `json:"status"`
+	CollectionID      *uuid.UUID `json:"collection_id,omitempty"`
+	Visibility        Visibility `json:"visibility,omitempty"`
+	Creator           string     `json:"creator"`
+	Genre             []string   `json:"genre"`
+	Tags              []string   `json:"tags"`
+	ReleaseYear       *int       `json:"release_year,omitempty"`
+	ReleaseDate       *Date      `json:"release_date,omitempty"`
+	CoverURL          string     `json:"cover_url"`
+	Notes             string     `json:"notes"`
+	NotesPrivate      *bool      `json:"notes_private,omitempty"`
+	Rating            *float64   `json:"rating,omitempty"`
+	RuntimeMinutes    *int       `json:"runtime_minutes,omitempty"`
+	DurationMinutes   *int       `json:"duration_minutes,omitempty"`
+	TimeToBeatMinutes *int       `json:"time_to_beat_minutes,omitempty"`
+	TMDBId            *string    `json:"tmdb_id,omitempty"`
+	MusicbrainzID     *string    `json:"musicbrainz_id,omitempty"`
+	IGDBId            *string    `json:"igdb_id,omitempty"`
+	EnrichMetadata    bool       `json:"enrich_metadata"`
+}
+
+// setExternalID stores id as the request's ID at provider unless one was
+// given explicitly.
+func (r *CreateRequest) setExternalID(provider, id string) {
+	var dst **string
+	switch provider {
+	case ExternalTMDB:
+		dst = &r.TMDBId
+	case ExternalMusicBrainz:
+		dst = &r.MusicbrainzID
+	case ExternalIGDB:
+		dst = &r.IGDBId
+	default:
+		return
+	}
+	if *dst == nil && id != "" {
+		*dst = &id
+	}
+}
+
+// normalizeExternalIDs validates the external IDs given for a new item and
+// puts them in canonical form.
+func (r *CreateRequest) normalizeExternalIDs() error {
+	for _, f := range []struct {
+		provider string
+		id       *string
+	}{
+		{ExternalTMDB, r.TMDBId},
+		{ExternalMusicBrainz, r.MusicbrainzID},
+		{ExternalIGDB, r.IGDBId},
+	} {
+		if f.id == nil {
+			continue
+		}
+		id, err := NormalizeExternalID(f.provider, r.MediaType, *f.id)
+		if err != nil {
+			return err
+		}
+		*f.id = id
+	}
+	return nil
 }
 
 // UpdateRequest is the payload for updating a media item.
 type UpdateRequest struct {
-	Title       *string  `json:"title,omitempty"`
-	Status      *Status  `json:"status,omitempty"`
-	Creator     *string  `json:"creator,omitempty"`
-	Genre       []string `json:"genre,omitempty"`
-	ReleaseYear *int     `json:"release_year,omitempty"`
-	CoverURL    *string  `json:"cover_url,omitempty"`
-	Notes       *string  `json:"notes,omitempty"`
-	Rating      *float64 `json:"rating,omitempty"`
+	Title             *string     `json:"title,omitempty"`
+	Status            *Status     `json:"status,omitempty"`
+	Visibility        *Visibility `json:"visibility,omitempty"`
+	Creator           *string     `json:"creator,omitempty"`
+	Genre             []string    `json:"genre,omitempty"`
+	Tags              []string    `json:"tags,omitempty"`
+	ReleaseYear       *int        `json:"release_year,omitempty"`
+	ReleaseDate       *Date       `json:"release_date,omitempty"`
+	CoverURL          *string     `json:"cover_url,omitempty"`
+	Notes             *string     `json:"notes,omitempty"`
+	NotesPrivate      *bool       `json:"notes_private,omitempty"`
+	Rating            *float64    `json:"rating,omitempty"`
+	RuntimeMinutes    *int        `json:"runtime_minutes,omitempty"`
+	DurationMinutes   *int        `json:"duration_minutes,omitempty"`
+	TimeToBeatMinutes *int        `json:"time_to_beat_minutes,omitempty"`
 }
 
 // StatusUpdateRequest is the payload for patching just the status.
@@ -78,12 +290,305 @@ type StatusUpdateRequest struct {
 	Status Status `json:"status"`
 }
 
-// ListFilter holds query parameters for listing media items.
+// CollectionRequest is the payload for moving an item into or out of a
+// shared collection. A nil CollectionID stops sharing the item.
+type CollectionRequest struct {
+	CollectionID *uuid.UUID `json:"collection_id"`
+}
+
+// ListFilter holds query parameters for listing media items. Items in shared
+// collections the user belongs to are included. Creator matches a substring
+// and TextQuery matches like a plain search query.
 type ListFilter struct {
+	UserID         uuid.UUID
+	MediaType      *MediaType
+	Status         *Status
+	Genre          *string
+	Tag            *string
+	Creator        *string
+	ReleaseYearMin *int
+	ReleaseYearMax *int
+	RatingMin      *float64
+	TextQuery      *string
+	CollectionID   *uuid.UUID
+	Page           int
+	PageSize       int
+}
+
+// Rating facet buckets. Ratings fall in the bucket whose lower bound they
+// reach; 10 belongs to the top bucket.
+const (
+	RatingBucketUnrated = "unrated"
+	RatingBucket0to2    = "0-2"
+	RatingBucket2to4    = "2-4"
+	RatingBucket4to6    = "4-6"
+	RatingBucket6to8    = "6-8"
+	RatingBucket8to10   = "8-10"
+)
+
+// SearchMode selects how a search query matches and ranks items.
+type SearchMode string
+
+const (
+	// SearchKeyword matches on the full-text index and trigram similarity.
+	SearchKeyword SearchMode = "keyword"
+	// SearchSemantic matches the items nearest to the query embedding.
+	SearchSemantic SearchMode = "semantic"
+	// SearchHybrid matches either way and blends both scores.
+	SearchHybrid SearchMode = "hybrid"
+)
+
+// SearchFilter holds a text query plus facet selections. Values selected
+// within one facet are alternatives; selections across facets all apply.
+// An empty Query matches every item. Where is an extra predicate over
+// media_items columns with ? placeholders for WhereArgs; it is spliced into
+// the SQL as is and must come from trusted code such as a query compiler.
+// Semantic and hybrid modes need the query's Embedding under EmbeddingModel.
+type SearchFilter struct {
+	UserID         uuid.UUID
+	Query          string
+	Mode           SearchMode
+	Embedding      embedding.Vector
+	EmbeddingModel string
+	Where          string
+	WhereArgs      []any
+	MediaTypes     []MediaType
+	Statuses       []Status
+	Genres         []string
+	Decades        []int
+	RatingBuckets  []string
+	Highlight      HighlightMarkers
+	Page           int
+	PageSize       int
+}
+
+// FacetCount is the number of matching items with one facet value.
+type FacetCount struct {
+	Value    string `json:"value"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// Facets holds counts per facet over the whole match set. Each facet is
+// counted with every selection applied except its own, so unselected values
+// show how many results choosing them would add.
+type Facets struct {
+	MediaType []FacetCount `json:"media_type"`
+	Status    []FacetCount `json:"status"`
+	Genre     []FacetCount `json:"genre"`
+	Decade    []FacetCount `json:"decade"`
+	Rating    []FacetCount `json:"rating"`
+}
+
+// HighlightMarkers wrap matched terms in search highlights.
+type HighlightMarkers struct {
+	Start string
+	Stop  string
+}
+
+// DefaultHighlightMarkers wrap matched terms in <mark> tags.
+var DefaultHighlightMarkers = HighlightMarkers{Start: "<mark>", Stop: "</mark>"}
+
+// Hit is a search match with its ranking score. Highlights maps each matched field (title, creator,
+// notes, overview) to HTML-escaped fragments with matched terms wrapped in
+// the requested markers.
+type Hit struct {
+	*Item
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// SearchResult is one page of search matches with facet counts.
+type SearchResult struct {
+	Items  []*Hit `json:"items"`
+	Total  int    `json:"total"`
+	Facets Facets `json:"facets"`
+}
+
+// SuggestionKind is the field an autocomplete suggestion completes.
+type SuggestionKind string
+
+const (
+	SuggestTitle   SuggestionKind = "title"
+	SuggestCreator SuggestionKind = "creator"
+	SuggestGenre   SuggestionKind = "genre"
+	SuggestTag     SuggestionKind = "tag"
+)
+
+// SuggestionKinds lists every kind in display order.
+var SuggestionKinds = []SuggestionKind{SuggestTitle, SuggestCreator, SuggestGenre, SuggestTag}
+
+// Suggestion is an autocomplete value drawn from the user's items. Score is
+// 1 for a prefix match, 0.9 for a match at the start of a later word and the
+// trigram word similarity otherwise. ItemID is set for titles and points at
+// the most recently updated item with that title.
+type Suggestion struct {
+	Value  string     `json:"value"`
+	Count  int        `json:"count"`
+	Score  float64    `json:"score"`
+	ItemID *uuid.UUID `json:"item_id,omitempty"`
+}
+
+// FeatureMatch is one feature an item shares with the item it is compared to.
+// Score is the feature's contribution to the overall similarity.
+type FeatureMatch struct {
+	Feature string   `json:"feature"`
+	Values  []string `json:"values"`
+	Score   float64  `json:"score"`
+}
+
+// SimilarItem is an item ranked by how much it has in common with another.
+type SimilarItem struct {
+	Item    *Item          `json:"item"`
+	Score   float64        `json:"score"`
+	Matches []FeatureMatch `json:"matches"`
+}
+
+// DiscoverFilter holds parameters for searching other users' public items.
+// ViewerID may be nil for anonymous requests; a signed-in follower also
+// matches followers-only items.
+type DiscoverFilter struct {
+	ViewerID   *uuid.UUID
+	Query      string
+	MediaTypes []MediaType
+	Page       int
+	PageSize   int
+}
+
+// DiscoverOwner is a user whose public collection holds a discovered title.
+type DiscoverOwner struct {
+	Username    string   `json:"username"`
+	DisplayName string   `json:"display_name"`
+	AvatarURL   string   `json:"avatar_url"`
+	Status      Status   `json:"status"`
+	Rating      *float64 `json:"rating,omitempty"`
+}
+
+// DiscoverTitle is a title found in public collections, with everyone who
+// holds it grouped together. Owners lists at most MaxDiscoverOwners of them,
+// highest rated first; OwnerCount counts all of them.
+type DiscoverTitle struct {
+	Title         string          `json:"title"`
+	MediaType     MediaType       `json:"media_type"`
+	Creator       string          `json:"creator"`
+	ReleaseYear   *int            `json:"release_year,omitempty"`
+	CoverURL      string          `json:"cover_url"`
+	OwnerCount    int             `json:"owner_count"`
+	AverageRating *float64        `json:"average_rating,omitempty"`
+	RatingCount   int             `json:"rating_count"`
+	Score         float64         `json:"score"`
+	Owners        []DiscoverOwner `json:"owners"`
+}
+
+// DiscoverResult is one page of discovered titles.
+type DiscoverResult struct {
+	Titles []*DiscoverTitle `json:"titles"`
+	Total  int              `json:"total"`
+}
+
+// DuplicatePair is two items in a collection that likely describe the same work.
+type DuplicatePair struct {
+	Item    *Item    `json:"item"`
+	Match   *Item    `json:"match"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// DuplicateMatch is an existing item that likely duplicates a prospective one.
+type DuplicateMatch struct {
+	Item    *Item    `json:"item"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// DuplicateCheckRequest describes a prospective item to check for duplicates.
+// Items sharing one of its external IDs are certain duplicates.
+type DuplicateCheckRequest struct {
+	Title         string    `json:"title"`
+	MediaType     MediaType `json:"media_type"`
+	Creator       string    `json:"creator"`
+	ReleaseYear   *int      `json:"release_year,omitempty"`
+	TMDBId        *string   `json:"tmdb_id,omitempty"`
+	MusicbrainzID *string   `json:"musicbrainz_id,omitempty"`
+	IGDBId        *string   `json:"igdb_id,omitempty"`
+}
+
+// ExternalIDRequest is the payload for linking an item to a provider ID.
+type ExternalIDRequest struct {
+	ExternalID string `json:"external_id"`
+}
+
+// MetadataChange is a field's value before and after fetching metadata from
+// a provider.
+type MetadataChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// MetadataRefresh records the fields one metadata fetch changed on an item.
+type MetadataRefresh struct {
+	ID          uuid.UUID                 `json:"id"`
+	MediaItemID uuid.UUID                 `json:"media_item_id"`
+	Provider    string                    `json:"provider"`
+	ExternalID  string                    `json:"external_id"`
+	Changes     map[string]MetadataChange `json:"changes"`
+	RefreshedAt time.Time                 `json:"refreshed_at"`
+}
+
+// MergeRequest is the payload for merging another item into the target item.
+type MergeRequest struct {
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+// PickFilter narrows the pool the random picker draws from.
+type PickFilter struct {
 	UserID    uuid.UUID
 	MediaType *MediaType
-	Status    *Status
+	Statuses  []Status
 	Genre     *string
-	Page      int
-	PageSize  int
+	Seed      *int64
+	// AsOf is when the pool is read: picks recorded before it count as
+	// recent and item ages are measured to it. Zero means now.
+	AsOf       time.Time
+	Count      int
+	RecentDays int
+	DryRun     bool
+}
+
+// Pick is an item drawn by the random picker.
+type Pick struct {
+	Item    *Item    `json:"item"`
+	Weight  float64  `json:"weight"`
+	Chance  float64  `json:"chance"`
+	Reasons []string `json:"reasons"`
+}
+
+// PickResult is the outcome of a random draw. Repeating the request with the
+// same seed against an unchanged pool yields the same picks.
+type PickResult struct {
+	Picks    []*Pick   `json:"picks"`
+	Seed     int64     `json:"seed"`
+	AsOf     time.Time `json:"as_of"`
+	PoolSize int       `json:"pool_size"`
+}
+
+// BacklogBucket sums the estimated time of unfinished items for one status
+// and media type.
+type BacklogBucket struct {
+	Status         Status    `json:"status"`
+	MediaType      MediaType `json:"media_type"`
+	Items          int       `json:"items"`
+	EstimatedItems int       `json:"estimated_items"`
+	Minutes        int       `json:"minutes"`
+}
+
+// BacklogEstimate is the remaining time across all unfinished items.
+// Items without a time estimate are counted but contribute no minutes.
+type BacklogEstimate struct {
+	TotalMinutes     int               `json:"total_minutes"`
+	TotalHours       float64           `json:"total_hours"`
+	UnestimatedItems int               `json:"unestimated_items"`
+	ByStatus         map[Status]int    `json:"by_status"`
+	ByType           map[MediaType]int `json:"by_type"`
+	Buckets          []BacklogBucket   `json:"buckets"`
 }
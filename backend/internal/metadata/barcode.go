@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+// ErrInvalidCode is returned by NormalizeCode for codes that are not a valid
+// UPC, EAN or ISBN.
+var ErrInvalidCode = errors.New("invalid code")
+
+// NormalizeCode strips separators from a barcode or ISBN, validates its check
+// digit and returns the digits. ISBN-10 codes are converted to ISBN-13.
+// Errors wrap ErrInvalidCode.
+func NormalizeCode(raw string) (string, error) {
+	code := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(raw)))
+	if code == "" {
+		return "", fmt.Errorf("%w: code is required", ErrInvalidCode)
+	}
+
+	if len(code) == 10 {
+		return isbn10To13(code)
+	}
+
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("%w: code must contain only digits", ErrInvalidCode)
+		}
+	}
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return "", fmt.Errorf("%w: code must be a UPC-A, EAN-8, EAN-13 or ISBN", ErrInvalidCode)
+	}
+	if !validGTIN(code) {
+		return "", fmt.Errorf("%w: invalid check digit", ErrInvalidCode)
+	}
+	return code, nil
+}
+
+// validGTIN verifies the GS1 mod-10 check digit shared by UPC and EAN codes.
+func validGTIN(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+func isbn10To13(code string) (string, error) {
+	sum := 0
+	for i, c := range code {
+		var d int
+		switch {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c == 'X' && i == 9:
+			d = 10
+		default:
+			return "", fmt.Errorf("%w: invalid ISBN-10", ErrInvalidCode)
+		}
+		sum += d * (10 - i)
+	}
+	if sum%11 != 0 {
+		return "", fmt.Errorf("%w: invalid check digit", ErrInvalidCode)
+	}
+
+	body := "978" + code[:9]
+	check := 0
+	for i, c := range body {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		check += d
+	}
+	return fmt.Sprintf("%s%d", body, (10-check%10)%10), nil
+}
+
+var (
+	bracketedRe   = regexp.MustCompile(`\s*[\(\[][^\)\]]*[\)\]]`)
+	formatTokenRe = regexp.MustCompile(`(?i)\b(4k ultra hd|ultra hd|4k|uhd|blu-?ray|dvd|digital( copy| hd)?|widescreen|full ?screen|special edition|collector'?s edition|steelbook|audio cd|cd|vinyl|lp|playstation ?\d|ps\d|xbox( one| series [xs]| 360)?|nintendo switch)\b`)
+	trailingSepRe = regexp.MustCompile(`[\s\-–:/,|+]+$`)
+)
+
+// cleanProductTitle strips packaging noise such as "(DVD, 1999)" or
+// "Blu-ray + Digital" from a retail product title.
+func cleanProductTitle(title string) string {
+	t := bracketedRe.ReplaceAllString(title, "")
+	t = formatTokenRe.ReplaceAllString(t, "")
+	t = strings.Join(strings.Fields(t), " ")
+	t = trailingSepRe.ReplaceAllString(t, "")
+	if t == "" {
+		return strings.TrimSpace(title)
+	}
+	return t
+}
+
+// mediaTypeFromCategory guesses a media type from a retail category path.
+func mediaTypeFromCategory(category string) media.MediaType {
+	c := strings.ToLower(category)
+	switch {
+	case strings.Contains(c, "video game"):
+		return media.MediaTypeGame
+	case strings.Contains(c, "music"), strings.Contains(c, "cds"), strings.Contains(c, "vinyl"):
+		return media.MediaTypeMusic
+	case strings.Contains(c, "movie"), strings.Contains(c, "dvd"),
+		strings.Contains(c, "blu-ray"), strings.Contains(c, "videos"):
+		return media.MediaTypeMovie
+	default:
+		return ""
+	}
+}
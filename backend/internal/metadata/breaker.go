@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops requests to a provider after threshold consecutive
+// failures. Once cooldown has passed a single trial request is let through;
+// if it succeeds the breaker closes, otherwise it stays open for another
+// cooldown.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker creates a CircuitBreaker. A non-positive threshold
+// disables it.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a request may be sent and, if not, how long until
+// the next trial request.
+func (b *CircuitBreaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true, 0
+	}
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return false, wait
+	}
+	if b.probing {
+		return false, 0
+	}
+	b.probing = true
+	return true, 0
+}
+
+// Record reports the outcome of an allowed request.
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Release ends an allowed request without an outcome, such as one the
+// caller cancelled.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
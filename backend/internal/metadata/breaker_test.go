@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := NewCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := b.Allow(); !ok {
+			t.Fatalf("breaker open after %d failures", i)
+		}
+		b.Record(true)
+	}
+	// A success resets the count of consecutive failures.
+	b.Record(false)
+	for i := 0; i < 2; i++ {
+		b.Record(true)
+	}
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("breaker open after a success and two failures")
+	}
+
+	b.Record(true)
+	ok, wait := b.Allow()
+	if ok {
+		t.Fatal("breaker closed after three consecutive failures")
+	}
+	if wait <= 0 || wait > time.Hour {
+		t.Errorf("retry in %s, want up to the one hour cooldown", wait)
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	b := NewCircuitBreaker(1, 10*time.Millisecond)
+	b.Record(true)
+	if ok, _ := b.Allow(); ok {
+		t.Fatal("breaker closed during cooldown")
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("no trial request allowed after cooldown")
+	}
+	if ok, _ := b.Allow(); ok {
+		t.Fatal("second request allowed while the trial is in flight")
+	}
+
+	// A failed trial keeps the breaker open for another cooldown.
+	b.Record(true)
+	if ok, _ := b.Allow(); ok {
+		t.Fatal("breaker closed after a failed trial")
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// A released trial lets the next caller probe instead.
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("no trial request allowed after second cooldown")
+	}
+	b.Release()
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("no trial request allowed after the first was released")
+	}
+
+	// A successful trial closes the breaker.
+	b.Record(false)
+	for i := 0; i < 3; i++ {
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("breaker open after a successful trial")
+		}
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Hour)
+	for i := 0; i < 100; i++ {
+		b.Record(true)
+	}
+	if ok, _ := b.Allow(); !ok {
+		t.Fatal("disabled breaker rejected a request")
+	}
+}
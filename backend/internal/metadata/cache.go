@@ -0,0 +1,230 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Cached provider operations.
+const (
+	opSearch  = "search"
+	opGetByID = "get"
+)
+
+type bypassKey struct{}
+
+// WithCacheBypass returns a context whose metadata lookups skip cached
+// responses. Fresh responses are still written to the cache.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+// CacheBypass is middleware that bypasses the metadata cache for requests
+// sent with Cache-Control: no-cache.
+func CacheBypass(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(strings.ToLower(r.Header.Get("Cache-Control")), "no-cache") {
+			r = r.WithContext(WithCacheBypass(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Cache stores provider responses in Postgres, keyed by provider, operation
+// and normalized query. Lookups that find nothing are cached for the shorter
+// negative TTL so that new releases show up soon.
+type Cache struct {
+	db          *pgxpool.Pool
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// NewCache creates a new metadata Cache.
+func NewCache(db *pgxpool.Pool, ttl, negativeTTL time.Duration) *Cache {
+	return &Cache{db: db, ttl: ttl, negativeTTL: negativeTTL}
+}
+
+// Wrap returns p with its Search and GetByID responses cached under name.
+func (c *Cache) Wrap(name string, p Provider) Provider {
+	return &cachedProvider{cache: c, name: name, next: p}
+}
+
+// get loads an unexpired entry into dst. found reports a cache hit, and
+// negative whether the entry records that nothing matched.
+func (c *Cache) get(ctx context.Context, provider, op, key string, dst any) (found, negative bool, err error) {
+	var data []byte
+	err = c.db.QueryRow(ctx, `
+		SELECT response FROM metadata_cache
+		WHERE provider = $1 AND operation = $2 AND query_key = $3 AND expires_at > now()
+	`, provider, op, key).Scan(&data)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("get cached metadata: %w", err)
+	}
+	if data == nil {
+		return true, true, nil
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return false, false, fmt.Errorf("unmarshal cached metadata: %w", err)
+	}
+	return true, false, nil
+}
+
+// put stores a response, or a negative entry when value is nil.
+func (c *Cache) put(ctx context.Context, provider, op, key string, value any) error {
+	var data []byte
+	ttl := c.negativeTTL
+	if value != nil {
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return fmt.Errorf("marshal metadata: %w", err)
+		}
+		ttl = c.ttl
+	}
+	_, err := c.db.Exec(ctx, `
+		INSERT INTO metadata_cache (provider, operation, query_key, response, expires_at)
+		VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+		ON CONFLICT (provider, operation, query_key) DO UPDATE
+		SET response = EXCLUDED.response, created_at = now(), expires_at = EXCLUDED.expires_at
+	`, provider, op, key, data, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("put cached metadata: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes cached responses, optionally only those of one provider or
+// only expired ones, and returns how many were removed.
+func (c *Cache) Purge(ctx context.Context, provider string, expiredOnly bool) (int64, error) {
+	result, err := c.db.Exec(ctx, `
+		DELETE FROM metadata_cache
+		WHERE ($1 = '' OR provider = $1) AND (NOT $2 OR expires_at <= now())
+	`, provider, expiredOnly)
+	if err != nil {
+		return 0, fmt.Errorf("purge metadata cache: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// RunCacheCleanup deletes expired cache entries once at start and then
+// every interval until ctx is cancelled.
+func RunCacheCleanup(ctx context.Context, cache *Cache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := cache.Purge(ctx, "", true)
+		if err != nil {
+			slog.Warn("metadata cache cleanup failed", "error", err)
+		} else if n > 0 {
+			slog.Info("metadata cache cleanup", "deleted", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// normalizeQuery lowercases a query and collapses whitespace so trivially
+// different lookups share a cache entry.
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// cachedProvider serves a provider's responses from the cache. Cache errors
+// are logged and fall through to the provider. Of provider errors only
+// ErrNotFound is cached, as a negative entry.
+type cachedProvider struct {
+	cache *Cache
+	name  string
+	next  Provider
+}
+
+// Search implements Provider. An empty result list is cached as negative.
+func (p *cachedProvider) Search(ctx context.Context, title string, year *int) ([]*Result, error) {
+	key := normalizeQuery(title)
+	if year != nil {
+		key += "|" + strconv.Itoa(*year)
+	}
+
+	if !cacheBypassed(ctx) {
+		var results []*Result
+		found, negative, err := p.cache.get(ctx, p.name, opSearch, key, &results)
+		if err != nil {
+			slog.Warn("metadata cache read failed", "provider", p.name, "error", err)
+		} else if found {
+			if negative {
+				return []*Result{}, nil
+			}
+			return results, nil
+		}
+	}
+
+	results, err := p.next.Search(ctx, title, year)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if len(results) > 0 {
+		value = results
+	}
+	if err := p.cache.put(ctx, p.name, opSearch, key, value); err != nil {
+		slog.Warn("metadata cache write failed", "provider", p.name, "error", err)
+	}
+	return results, nil
+}
+
+// GetByID implements Provider. IDs the provider does not know are cached as
+// negative.
+func (p *cachedProvider) GetByID(ctx context.Context, id string) (*Result, error) {
+	key := strings.TrimSpace(id)
+
+	if !cacheBypassed(ctx) {
+		var result Result
+		found, negative, err := p.cache.get(ctx, p.name, opGetByID, key, &result)
+		if err != nil {
+			slog.Warn("metadata cache read failed", "provider", p.name, "error", err)
+		} else if found {
+			if negative {
+				return nil, fmt.Errorf("%s %s: %w", p.name, key, ErrNotFound)
+			}
+			return &result, nil
+		}
+	}
+
+	result, err := p.next.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			if err := p.cache.put(ctx, p.name, opGetByID, key, nil); err != nil {
+				slog.Warn("metadata cache write failed", "provider", p.name, "error", err)
+			}
+		}
+		return nil, err
+	}
+	if result != nil {
+		if err := p.cache.put(ctx, p.name, opGetByID, key, result); err != nil {
+			slog.Warn("metadata cache write failed", "provider", p.name, "error", err)
+		}
+	}
+	return result, nil
+}
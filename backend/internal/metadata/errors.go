@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody caps how much of an error response is kept in a StatusError.
+const maxErrorBody = 512
+
+// ErrNotFound matches a *StatusError for a 404 response with errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// StatusError reports a non-2xx response from a provider.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+// newStatusError reads the start of resp's body into a StatusError and
+// closes it.
+func newStatusError(provider string, resp *http.Response) *StatusError {
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody)) //nolint:errcheck
+	return &StatusError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s: HTTP %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Is reports whether target is ErrNotFound and the response was a 404.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Temporary reports whether the request may succeed if repeated.
+func (e *StatusError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// CircuitOpenError reports that a provider's circuit breaker is open and the
+// request was not sent.
+type CircuitOpenError struct {
+	Provider string
+	RetryIn  time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable, retry in %s", e.Provider, e.RetryIn.Round(time.Second))
+}
+
+// Temporary reports that the request may succeed once the breaker closes.
+func (e *CircuitOpenError) Temporary() bool { return true }
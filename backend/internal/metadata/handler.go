@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/your-org/ems/internal/httputil"
@@ -10,15 +11,18 @@ import (
 
 // Handler handles HTTP requests for metadata search.
 type Handler struct {
-	svc *Service
+	svc   *Service
+	cache *Cache
 }
 
-// NewHandler creates a new metadata Handler.
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+// NewHandler creates a new metadata Handler. cache may be nil when
+// response caching is disabled.
+func NewHandler(svc *Service, cache *Cache) *Handler {
+	return &Handler{svc: svc, cache: cache}
 }
 
-// Search handles POST /api/metadata/search.
+// Search handles POST /api/metadata/search. Responses come from the cache
+// unless the request is sent with Cache-Control: no-cache.
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Title     string          `json:"title"`
@@ -42,3 +46,60 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 
 	httputil.WriteJSON(w, http.StatusOK, results)
 }
+
+// Lookup handles POST /api/media/lookup. It resolves a barcode or ISBN to a
+// create request that can be posted to /api/media unchanged. media_type is
+// required when the product's category does not reveal it.
+func (h *Handler) Lookup(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code      string          `json:"code"`
+		MediaType media.MediaType `json:"media_type,omitempty"`
+		Status    media.Status    `json:"status,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	switch req.MediaType {
+	case "", media.MediaTypeMovie, media.MediaTypeMusic, media.MediaTypeGame:
+	default:
+		httputil.WriteError(w, http.StatusBadRequest, "media_type must be movie, music or game")
+		return
+	}
+
+	createReq, err := h.svc.LookupCode(r.Context(), req.Code, req.MediaType)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCode):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrNotFound):
+			httputil.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrUnknownMediaType):
+			httputil.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			httputil.WriteError(w, http.StatusBadGateway, err.Error())
+		}
+		return
+	}
+	if req.Status != "" {
+		createReq.Status = req.Status
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, createReq)
+}
+
+// PurgeCache handles DELETE /api/admin/metadata/cache. provider limits the
+// purge to one provider and expired=true to entries past their TTL.
+func (h *Handler) PurgeCache(w http.ResponseWriter, r *http.Request) {
+	var deleted int64
+	if h.cache != nil {
+		var err error
+		deleted, err = h.cache.Purge(r.Context(), r.URL.Query().Get("provider"), r.URL.Query().Get("expired") == "true")
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{"deleted": deleted})
+}
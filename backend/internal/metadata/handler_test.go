@@ -0,0 +1,44 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+func TestLookupStatus(t *testing.T) {
+	var looked string
+	lookup := codeLookupFunc(func(_ context.Context, code string) (*Result, error) {
+		looked = code
+		return &Result{Title: "Heat", MediaType: media.MediaTypeMovie}, nil
+	})
+	h := NewHandler(NewService(NewRegistry(), lookup), nil)
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{`{"code":""}`, http.StatusBadRequest, ""},
+		{`{"code":"4006381333932"}`, http.StatusBadRequest, ""},
+		{`{"code":"not a code"}`, http.StatusBadRequest, ""},
+		{`{"code":"400-6381-33393-1"}`, http.StatusOK, "4006381333931"},
+		{`{"code":"0-306-40615-2"}`, http.StatusOK, "9780306406157"},
+	}
+
+	for _, tt := range tests {
+		looked = ""
+		rec := httptest.NewRecorder()
+		h.Lookup(rec, httptest.NewRequest(http.MethodPost, "/api/media/lookup", strings.NewReader(tt.body)))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("Lookup(%s) status = %d, want %d: %s", tt.body, rec.Code, tt.wantStatus, rec.Body)
+		}
+		if looked != tt.wantCode {
+			t.Errorf("Lookup(%s) looked up %q, want %q", tt.body, looked, tt.wantCode)
+		}
+	}
+}
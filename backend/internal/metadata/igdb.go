@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/your-org/ems/internal/media"
 )
 
 // IGDBClient fetches game metadata from IGDB using OAuth2 client credentials.
@@ -24,21 +27,23 @@ type IGDBClient struct {
 	tokenExpiry  time.Time
 }
 
-// NewIGDBClient creates a new IGDB client.
-func NewIGDBClient(clientID, clientSecret string) *IGDBClient {
+// NewIGDBClient creates a new IGDB client configured by cfg.
+func NewIGDBClient(clientID, clientSecret string, cfg ClientConfig) *IGDBClient {
 	return &IGDBClient{
 		clientID:     clientID,
 		clientSecret: clientSecret,
-		httpClient:   &http.Client{},
+		httpClient:   newHTTPClient(ProviderIGDB, cfg),
 	}
 }
 
-func (c *IGDBClient) ensureToken(ctx context.Context) error {
+// ensureToken returns the current access token, fetching a new one if there
+// is none or it has expired.
+func (c *IGDBClient) ensureToken(ctx context.Context) (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		return nil
+		return c.accessToken, nil
 	}
 
 	params := url.Values{
@@ -50,13 +55,13 @@ func (c *IGDBClient) ensureToken(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
 		"https://id.twitch.tv/oauth2/token", strings.NewReader(params.Encode()))
 	if err != nil {
-		return fmt.Errorf("build token request: %w", err)
+		return "", fmt.Errorf("build token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("get igdb token: %w", err)
+		return "", fmt.Errorf("get igdb token: %w", err)
 	}
 	defer resp.Body.Close() //nolint:errcheck
 
@@ -65,18 +70,45 @@ func (c *IGDBClient) ensureToken(ctx context.Context) error {
 		ExpiresIn   int    `json:"expires_in"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return fmt.Errorf("decode token: %w", err)
+		return "", fmt.Errorf("decode token: %w", err)
 	}
 
 	c.accessToken = tokenResp.AccessToken
 	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-	return nil
+	return c.accessToken, nil
+}
+
+// invalidateToken drops token so the next query fetches a new one, unless
+// another query has already replaced it.
+func (c *IGDBClient) invalidateToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken == token {
+		c.accessToken = ""
+	}
 }
 
+// doQuery sends an APIcalypse query to endpoint. A token that IGDB rejects
+// before its expiry, for example after the client secret was rotated, is
+// replaced and the query retried once.
 func (c *IGDBClient) doQuery(ctx context.Context, endpoint, body string) ([]byte, error) {
-	if err := c.ensureToken(ctx); err != nil {
+	token, err := c.ensureToken(ctx)
+	if err != nil {
 		return nil, err
 	}
+	data, err := c.query(ctx, endpoint, body, token)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized {
+		c.invalidateToken(token)
+		if token, err = c.ensureToken(ctx); err != nil {
+			return nil, err
+		}
+		data, err = c.query(ctx, endpoint, body, token)
+	}
+	return data, err
+}
+
+func (c *IGDBClient) query(ctx context.Context, endpoint, body, token string) ([]byte, error) {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
 		"https://api.igdb.com/v4/"+endpoint, bytes.NewBufferString(body))
@@ -84,7 +116,7 @@ func (c *IGDBClient) doQuery(ctx context.Context, endpoint, body string) ([]byte
 		return nil, fmt.Errorf("build igdb request: %w", err)
 	}
 	req.Header.Set("Client-ID", c.clientID)
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "text/plain")
 
 	resp, err := c.httpClient.Do(req)
@@ -121,8 +153,11 @@ type igdbGame struct {
 
 func parseGame(g igdbGame, id string) *Result {
 	yr := 0
+	releaseDate := ""
 	if g.FirstRelease > 0 {
-		yr = time.Unix(g.FirstRelease, 0).Year()
+		released := time.Unix(g.FirstRelease, 0).UTC()
+		yr = released.Year()
+		releaseDate = released.Format("2006-01-02")
 	}
 	coverURL := ""
 	if g.Cover != nil {
@@ -146,7 +181,9 @@ func parseGame(g igdbGame, id string) *Result {
 		Genres:      genres,
 		CoverURL:    coverURL,
 		ReleaseYear: yr,
+		ReleaseDate: releaseDate,
 		Overview:    g.Summary,
+		MediaType:   media.MediaTypeGame,
 	}
 }
 
@@ -179,8 +216,12 @@ func (c *IGDBClient) GetByID(ctx context.Context, id string) (*Result, error) {
 	if c.clientID == "" {
 		return nil, fmt.Errorf("IGDB credentials not configured")
 	}
+	gameID, err := strconv.Atoi(id)
+	if err != nil || gameID <= 0 {
+		return nil, fmt.Errorf("invalid IGDB id %q", id)
+	}
 
-	query := fmt.Sprintf(`fields name,summary,first_release_date,cover.url,genres.name,involved_companies.developer,involved_companies.company.name; where id=%s;`, id)
+	query := fmt.Sprintf(`fields name,summary,first_release_date,cover.url,genres.name,involved_companies.developer,involved_companies.company.name; where id=%d;`, gameID)
 	data, err := c.doQuery(ctx, "games", query)
 	if err != nil {
 		return nil, err
@@ -191,8 +232,38 @@ func (c *IGDBClient) GetByID(ctx context.Context, id string) (*Result, error) {
 		return nil, fmt.Errorf("decode igdb detail: %w", err)
 	}
 	if len(games) == 0 {
-		return nil, fmt.Errorf("game not found")
+		return nil, fmt.Errorf("game %w", ErrNotFound)
+	}
+
+	result := parseGame(games[0], id)
+	if minutes, err := c.timeToBeat(ctx, gameID); err == nil {
+		result.TimeToBeatMinutes = minutes
 	}
+	return result, nil
+}
 
-	return parseGame(games[0], id), nil
+// timeToBeat returns the typical completion time of a game in minutes,
+// preferring the "normally" estimate over the "hastily" one.
+func (c *IGDBClient) timeToBeat(ctx context.Context, gameID int) (int, error) {
+	query := fmt.Sprintf(`fields hastily,normally,completely; where game_id=%d;`, gameID)
+	data, err := c.doQuery(ctx, "game_time_to_beats", query)
+	if err != nil {
+		return 0, err
+	}
+
+	var ttb []struct {
+		Hastily  int `json:"hastily"`
+		Normally int `json:"normally"`
+	}
+	if err := json.Unmarshal(data, &ttb); err != nil {
+		return 0, fmt.Errorf("decode igdb time to beat: %w", err)
+	}
+	if len(ttb) == 0 {
+		return 0, nil
+	}
+	seconds := ttb[0].Normally
+	if seconds == 0 {
+		seconds = ttb[0].Hastily
+	}
+	return (seconds + 30) / 60, nil
 }
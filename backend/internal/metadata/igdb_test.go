@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestIGDBQueryRenewsRejectedToken(t *testing.T) {
+	tokens, queries := 0, 0
+	api := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status, body := http.StatusOK, "[]"
+		switch req.URL.Host {
+		case "id.twitch.tv":
+			tokens++
+			body = fmt.Sprintf(`{"access_token":"token-%d","expires_in":3600}`, tokens)
+		case "api.igdb.com":
+			queries++
+			// The first token is revoked early.
+			if req.Header.Get("Authorization") == "Bearer token-1" {
+				status, body = http.StatusUnauthorized, `{"message":"invalid token"}`
+			}
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	c := &IGDBClient{clientID: "id", clientSecret: "secret", httpClient: &http.Client{Transport: newTestTransport(api)}}
+
+	data, err := c.doQuery(context.Background(), "games", "fields name;")
+	if err != nil {
+		t.Fatalf("doQuery error: %v", err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("doQuery = %q, want %q", data, "[]")
+	}
+	if tokens != 2 || queries != 2 {
+		t.Errorf("fetched %d tokens for %d queries, want 2 and 2", tokens, queries)
+	}
+
+	// The renewed token is reused.
+	if _, err := c.doQuery(context.Background(), "games", "fields name;"); err != nil {
+		t.Fatalf("second doQuery error: %v", err)
+	}
+	if tokens != 2 {
+		t.Errorf("fetched %d tokens, want the renewed token reused", tokens)
+	}
+}
+
+func TestIGDBQueryRetriesRejectedTokenOnce(t *testing.T) {
+	queries := 0
+	api := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status, body := http.StatusOK, `{"access_token":"token","expires_in":3600}`
+		if req.URL.Host == "api.igdb.com" {
+			queries++
+			status, body = http.StatusUnauthorized, ""
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	c := &IGDBClient{clientID: "id", clientSecret: "secret", httpClient: &http.Client{Transport: newTestTransport(api)}}
+
+	if _, err := c.doQuery(context.Background(), "games", "fields name;"); err == nil {
+		t.Fatal("doQuery succeeded with every token rejected")
+	}
+	if queries != 2 {
+		t.Errorf("sent %d queries, want the rejected query retried once", queries)
+	}
+}
@@ -7,20 +7,21 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"time"
+
+	"github.com/your-org/ems/internal/media"
 )
 
-// MusicBrainzClient fetches music metadata from MusicBrainz (no key, 1 req/s).
+// MusicBrainzClient fetches music metadata from MusicBrainz. No key is
+// needed, but anonymous clients are limited to one request per second.
 type MusicBrainzClient struct {
 	httpClient *http.Client
 	baseURL    string
-	lastReq    time.Time
 }
 
-// NewMusicBrainzClient creates a new MusicBrainz client.
-func NewMusicBrainzClient() *MusicBrainzClient {
+// NewMusicBrainzClient creates a new MusicBrainz client configured by cfg.
+func NewMusicBrainzClient(cfg ClientConfig) *MusicBrainzClient {
 	return &MusicBrainzClient{
-		httpClient: &http.Client{},
+		httpClient: newHTTPClient(ProviderMusicBrainz, cfg),
 		baseURL:    "https://musicbrainz.org/ws/2",
 	}
 }
@@ -43,13 +44,24 @@ type mbSearchResponse struct {
 
 // Search searches for releases by title.
 func (c *MusicBrainzClient) Search(ctx context.Context, title string, year *int) ([]*Result, error) {
-	if time.Since(c.lastReq) < time.Second {
-		time.Sleep(time.Second - time.Since(c.lastReq))
+	return c.searchReleases(ctx, fmt.Sprintf("release:%s", url.QueryEscape(title)))
+}
+
+// LookupCode finds a release by its UPC or EAN barcode.
+func (c *MusicBrainzClient) LookupCode(ctx context.Context, code string) (*Result, error) {
+	results, err := c.searchReleases(ctx, "barcode:"+code)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("release %w", ErrNotFound)
 	}
-	c.lastReq = time.Now()
+	return results[0], nil
+}
 
+func (c *MusicBrainzClient) searchReleases(ctx context.Context, query string) ([]*Result, error) {
 	params := url.Values{
-		"query": {fmt.Sprintf("release:%s", url.QueryEscape(title))},
+		"query": {query},
 		"fmt":   {"json"},
 		"limit": {"5"},
 	}
@@ -92,6 +104,8 @@ func (c *MusicBrainzClient) Search(ctx context.Context, title string, year *int)
 			Creator:     creator,
 			Genres:      genres,
 			ReleaseYear: yr,
+			ReleaseDate: fullDate(r.Date),
+			MediaType:   media.MediaTypeMusic,
 		})
 	}
 	return results, nil
@@ -99,14 +113,9 @@ func (c *MusicBrainzClient) Search(ctx context.Context, title string, year *int)
 
 // GetByID fetches a release by MusicBrainz ID.
 func (c *MusicBrainzClient) GetByID(ctx context.Context, id string) (*Result, error) {
-	if time.Since(c.lastReq) < time.Second {
-		time.Sleep(time.Second - time.Since(c.lastReq))
-	}
-	c.lastReq = time.Now()
-
-	params := url.Values{"inc": {"artist-credits genres"}, "fmt": {"json"}}
+	params := url.Values{"inc": {"artist-credits genres recordings"}, "fmt": {"json"}}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		c.baseURL+"/release/"+id+"?"+params.Encode(), nil)
+		c.baseURL+"/release/"+url.PathEscape(id)+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
@@ -119,13 +128,22 @@ func (c *MusicBrainzClient) GetByID(ctx context.Context, id string) (*Result, er
 	defer resp.Body.Close() //nolint:errcheck
 
 	var release struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
-		Date  string `json:"date"`
+		ID           string `json:"id"`
+		Title        string `json:"title"`
+		Date         string `json:"date"`
 		ArtistCredit []struct {
-			Artist struct{ Name string `json:"name"` } `json:"artist"`
+			Artist struct {
+				Name string `json:"name"`
+			} `json:"artist"`
 		} `json:"artist-credit"`
-		Genres []struct{ Name string `json:"name"` } `json:"genres"`
+		Genres []struct {
+			Name string `json:"name"`
+		} `json:"genres"`
+		Media []struct {
+			Tracks []struct {
+				Length int `json:"length"`
+			} `json:"tracks"`
+		} `json:"media"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return nil, fmt.Errorf("decode mb release: %w", err)
@@ -143,12 +161,21 @@ func (c *MusicBrainzClient) GetByID(ctx context.Context, id string) (*Result, er
 	for _, g := range release.Genres {
 		genres = append(genres, g.Name)
 	}
+	lengthMs := 0
+	for _, m := range release.Media {
+		for _, t := range m.Tracks {
+			lengthMs += t.Length
+		}
+	}
 
 	return &Result{
-		ExternalID:  id,
-		Title:       release.Title,
-		Creator:     creator,
-		Genres:      genres,
-		ReleaseYear: yr,
+		ExternalID:      id,
+		Title:           release.Title,
+		Creator:         creator,
+		Genres:          genres,
+		ReleaseYear:     yr,
+		ReleaseDate:     fullDate(release.Date),
+		MediaType:       media.MediaTypeMusic,
+		DurationMinutes: (lengthMs + 30000) / 60000,
 	}, nil
 }
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter is how long a provider is left alone after a 429
+// response without a usable Retry-After header.
+const defaultRetryAfter = 5 * time.Second
+
+// RateLimiter spaces out requests to one provider. Each caller reserves the
+// next free slot, so concurrent requests queue in order instead of racing,
+// and a provider asking callers to back off pushes every later slot back.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perSecond requests per second.
+// A non-positive rate only enforces provider back-off.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	l := &RateLimiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// Wait blocks until the caller may send a request or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	start := time.Now()
+	if l.next.After(start) {
+		start = l.next
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Hand the slot back if nobody has queued behind it.
+		l.mu.Lock()
+		if l.next.Equal(start.Add(l.interval)) {
+			l.next = start
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Backoff holds off every request for at least d.
+func (l *RateLimiter) Backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// RateLimitError reports that a provider rejected a request as over its rate
+// limit. Later requests are already delayed by RetryAfter.
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limit exceeded, retry after %s", e.Provider, e.RetryAfter)
+}
+
+// Temporary reports that the request may succeed later.
+func (e *RateLimitError) Temporary() bool { return true }
+
+// rateLimitedTransport waits for the provider's limiter before each request
+// and applies the Retry-After of 429 and 503 responses to it. 429 responses
+// are turned into a *RateLimitError.
+type rateLimitedTransport struct {
+	provider string
+	limiter  *RateLimiter
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			req.Body.Close() //nolint:errcheck
+		}
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			wait = defaultRetryAfter
+		}
+		t.limiter.Backoff(wait)
+		resp.Body.Close() //nolint:errcheck
+		return nil, &RateLimitError{Provider: t.provider, RetryAfter: wait}
+	case http.StatusServiceUnavailable:
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			t.limiter.Backoff(wait)
+		}
+	}
+	return resp, nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	l := NewRateLimiter(50) // one slot every 20ms
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+	}
+	// The first request goes at once and the other three wait a slot each.
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("four requests took %s, want at least 60ms", elapsed)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := NewRateLimiter(0)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unlimited requests took %s", elapsed)
+	}
+}
+
+func TestRateLimiterCancelledWaitReturnsSlot(t *testing.T) {
+	l := NewRateLimiter(10) // one slot every 100ms
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The abandoned slot is reused rather than pushing the next caller back.
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("next request waited %s, want at most the remaining 90ms", elapsed)
+	}
+}
+
+func TestRateLimiterBackoff(t *testing.T) {
+	l := NewRateLimiter(0)
+	l.Backoff(50 * time.Millisecond)
+	// A shorter back-off does not shorten the longer one.
+	l.Backoff(time.Millisecond)
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("request after back-off waited %s, want about 50ms", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v, want %s, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(future); !ok || got <= 58*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(%q) = %s, %v, want about an hour", future, got, ok)
+	}
+}
+
+func TestRateLimitedTransportBacksOffOn429(t *testing.T) {
+	l := NewRateLimiter(0)
+	tr := &rateLimitedTransport{
+		provider: "test",
+		limiter:  l,
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"30"}},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}),
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://provider.test/", nil)
+
+	_, err := tr.RoundTrip(req)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 30*time.Second {
+		t.Fatalf("RoundTrip error = %v, want a *RateLimitError retrying after 30s", err)
+	}
+
+	// Later requests wait out the back-off.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait during back-off = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+// Provider names used in registrations and provider chains.
+const (
+	ProviderTMDB        = "tmdb"
+	ProviderMusicBrainz = "musicbrainz"
+	ProviderIGDB        = "igdb"
+	ProviderUPCItemDB   = "upcitemdb"
+)
+
+// Registration is a provider registered for a media type.
+type Registration struct {
+	Name     string
+	Provider Provider
+	Priority int
+}
+
+// Registry holds the metadata providers for each media type, ordered by
+// priority. It is built at startup and read-only afterwards.
+type Registry struct {
+	byType map[media.MediaType][]Registration
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byType: make(map[media.MediaType][]Registration)}
+}
+
+// Register adds a provider for the given media types. Providers with a
+// higher priority are tried first; equal priorities keep registration order.
+// Registering a name again for a media type replaces the earlier entry.
+func (r *Registry) Register(name string, p Provider, priority int, mediaTypes ...media.MediaType) {
+	for _, mt := range mediaTypes {
+		regs := r.byType[mt]
+		for i, reg := range regs {
+			if reg.Name == name {
+				regs = append(regs[:i], regs[i+1:]...)
+				break
+			}
+		}
+		regs = append(regs, Registration{Name: name, Provider: p, Priority: priority})
+		sort.SliceStable(regs, func(i, j int) bool { return regs[i].Priority > regs[j].Priority })
+		r.byType[mt] = regs
+	}
+}
+
+// Providers returns the providers for a media type in the order they
+// should be tried.
+func (r *Registry) Providers(mediaType media.MediaType) []Registration {
+	return r.byType[mediaType]
+}
+
+// Lookup returns the provider registered under name for any media type.
+func (r *Registry) Lookup(name string) (Provider, bool) {
+	for _, regs := range r.byType {
+		for _, reg := range regs {
+			if reg.Name == name {
+				return reg.Provider, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// BuildRegistry registers providers by name following per-media-type chains,
+// the first name in a chain receiving the highest priority. Every name in a
+// chain must be a key of providers.
+func BuildRegistry(providers map[string]Provider, chains map[media.MediaType][]string) (*Registry, error) {
+	reg := NewRegistry()
+	for mt, names := range chains {
+		for i, name := range names {
+			p, ok := providers[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown metadata provider %q for %s", name, mt)
+			}
+			reg.Register(name, p, len(names)-i, mt)
+		}
+	}
+	return reg, nil
+}
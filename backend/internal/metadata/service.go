@@ -2,40 +2,86 @@ package metadata
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/your-org/ems/internal/media"
 )
 
-// Service dispatches metadata searches by media type.
+// ErrUnknownMediaType is returned by LookupCode when the product found does
+// not say whether it is a movie, music or game and the caller gave no type.
+var ErrUnknownMediaType = errors.New("media type of the product is unknown; pass media_type")
+
+// Service dispatches metadata lookups to the providers registered for each
+// media type, falling back through them in priority order.
 type Service struct {
-	tmdb        *TMDBClient
-	musicbrainz *MusicBrainzClient
-	igdb        *IGDBClient
+	registry    *Registry
+	codeLookups []CodeLookup
 }
 
-// NewService creates a new metadata Service.
-func NewService(tmdb *TMDBClient, mb *MusicBrainzClient, igdb *IGDBClient) *Service {
-	return &Service{tmdb: tmdb, musicbrainz: mb, igdb: igdb}
+// NewService creates a new metadata Service. Barcode lookups try
+// codeLookups in order.
+func NewService(registry *Registry, codeLookups ...CodeLookup) *Service {
+	return &Service{registry: registry, codeLookups: codeLookups}
 }
 
-// Search dispatches to the correct provider based on media type.
+// Search returns the results of the first provider for the media type that
+// finds anything. A provider that fails or finds nothing falls through to
+// the next; the last error is returned only if none of them succeeded.
 func (s *Service) Search(ctx context.Context, title string, mediaType media.MediaType, year *int) ([]*Result, error) {
-	switch mediaType {
-	case media.MediaTypeMovie:
-		return s.tmdb.Search(ctx, title, year)
-	case media.MediaTypeMusic:
-		return s.musicbrainz.Search(ctx, title, year)
-	case media.MediaTypeGame:
-		return s.igdb.Search(ctx, title, year)
-	default:
-		return nil, fmt.Errorf("unknown media type: %s", mediaType)
+	results, _, err := s.search(ctx, title, mediaType, year)
+	return results, err
+}
+
+// search is Search that also returns the provider whose results are used.
+func (s *Service) search(ctx context.Context, title string, mediaType media.MediaType, year *int) ([]*Result, Registration, error) {
+	regs := s.registry.Providers(mediaType)
+	if len(regs) == 0 {
+		return nil, Registration{}, fmt.Errorf("no metadata provider for media type: %s", mediaType)
+	}
+
+	var (
+		lastErr   error
+		succeeded bool
+	)
+	for _, reg := range regs {
+		found, err := reg.Provider.Search(ctx, title, year)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, Registration{}, ctx.Err()
+			}
+			slog.Warn("metadata provider search failed", "provider", reg.Name, "media_type", mediaType, "error", err)
+			lastErr = fmt.Errorf("%s: %w", reg.Name, err)
+			continue
+		}
+		succeeded = true
+
+		// Providers covering several media types may return other kinds, or
+		// results of a kind they could not tell.
+		results := make([]*Result, 0, len(found))
+		for _, r := range found {
+			if r.MediaType == mediaType {
+				r.Provider = reg.Name
+				results = append(results, r)
+			}
+		}
+		if len(results) > 0 {
+			return results, reg, nil
+		}
 	}
+	if !succeeded {
+		return nil, Registration{}, lastErr
+	}
+	return []*Result{}, Registration{}, nil
 }
 
-// Enrich fetches the best metadata match and returns it as a map.
+// Enrich fetches the best metadata match and returns it as a map. The match
+// is re-fetched by ID from the provider that found it, because search
+// results omit details such as runtime.
 func (s *Service) Enrich(ctx context.Context, title string, mediaType media.MediaType, releaseYear *int) (map[string]any, error) {
-	results, err := s.Search(ctx, title, mediaType, releaseYear)
+	results, reg, err := s.search(ctx, title, mediaType, releaseYear)
 	if err != nil {
 		return nil, fmt.Errorf("metadata search: %w", err)
 	}
@@ -44,13 +90,159 @@ func (s *Service) Enrich(ctx context.Context, title string, mediaType media.Medi
 	}
 
 	r := results[0]
+	detail, err := reg.Provider.GetByID(ctx, r.ExternalID)
+	if err != nil {
+		slog.Warn("metadata detail fetch failed", "provider", reg.Name, "external_id", r.ExternalID, "error", err)
+	} else {
+		r = mergeDetail(r, detail)
+	}
+
+	return enrichment(r, reg.Name), nil
+}
+
+// EnrichByID fetches metadata for a known provider ID, such as one stored
+// on an item, and returns it in the same form as Enrich.
+func (s *Service) EnrichByID(ctx context.Context, provider, externalID string) (map[string]any, error) {
+	p, ok := s.registry.Lookup(provider)
+	if !ok {
+		return nil, fmt.Errorf("metadata provider %s is not configured", provider)
+	}
+	r, err := p.GetByID(ctx, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("metadata get %s %s: %w", provider, externalID, err)
+	}
+	return enrichment(r, provider), nil
+}
+
+// enrichment converts a result into the map stored as item metadata.
+func enrichment(r *Result, source string) map[string]any {
 	return map[string]any{
-		"external_id":  r.ExternalID,
-		"cover_url":    r.CoverURL,
-		"creator":      r.Creator,
-		"genres":       r.Genres,
-		"release_year": r.ReleaseYear,
-		"overview":     r.Overview,
-		"source":       string(mediaType),
-	}, nil
+		"external_id":          r.ExternalID,
+		"cover_url":            r.CoverURL,
+		"creator":              r.Creator,
+		"genres":               r.Genres,
+		"release_year":         r.ReleaseYear,
+		"release_date":         r.ReleaseDate,
+		"overview":             r.Overview,
+		"source":               source,
+		"runtime_minutes":      r.RuntimeMinutes,
+		"duration_minutes":     r.DurationMinutes,
+		"time_to_beat_minutes": r.TimeToBeatMinutes,
+	}
+}
+
+// mergeDetail overlays the non-empty fields of a detail lookup onto a
+// search result.
+func mergeDetail(r, detail *Result) *Result {
+	merged := *r
+	if detail.Creator != "" {
+		merged.Creator = detail.Creator
+	}
+	if len(detail.Genres) > 0 {
+		merged.Genres = detail.Genres
+	}
+	if detail.CoverURL != "" {
+		merged.CoverURL = detail.CoverURL
+	}
+	if detail.ReleaseDate != "" {
+		merged.ReleaseDate = detail.ReleaseDate
+	}
+	if detail.Overview != "" {
+		merged.Overview = detail.Overview
+	}
+	merged.RuntimeMinutes = detail.RuntimeMinutes
+	merged.DurationMinutes = detail.DurationMinutes
+	merged.TimeToBeatMinutes = detail.TimeToBeatMinutes
+	return &merged
+}
+
+// fullDate returns s if it is a complete YYYY-MM-DD date, otherwise "".
+// Providers report partial dates such as "1997" or "1997-05" for some releases.
+func fullDate(s string) string {
+	if len(s) != len("2006-01-02") {
+		return ""
+	}
+	if _, err := time.Parse("2006-01-02", s); err != nil {
+		return ""
+	}
+	return s
+}
+
+// LookupCode resolves a barcode or ISBN into a pre-filled create request.
+// Retail product lookups are followed by a title search against the media
+// type's provider to fill in creator, genres and release year. mediaType,
+// when set, overrides the type guessed from the product. The error wraps
+// ErrInvalidCode if code is malformed and ErrNotFound only if every lookup
+// found nothing.
+func (s *Service) LookupCode(ctx context.Context, code string, mediaType media.MediaType) (*media.CreateRequest, error) {
+	code, err := NormalizeCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		found   *Result
+		lastErr error
+	)
+	for _, p := range s.codeLookups {
+		r, err := p.LookupCode(ctx, code)
+		if err != nil {
+			// A failed lookup outranks a miss, since it might have matched.
+			if lastErr == nil || !errors.Is(err, ErrNotFound) {
+				lastErr = err
+			}
+			continue
+		}
+		found = r
+		break
+	}
+	if found == nil {
+		if lastErr == nil {
+			return nil, fmt.Errorf("no barcode lookup configured")
+		}
+		return nil, fmt.Errorf("no match for code %s: %w", code, lastErr)
+	}
+
+	req := &media.CreateRequest{
+		Title:     found.Title,
+		MediaType: found.MediaType,
+		Status:    media.StatusOwned,
+		Creator:   found.Creator,
+		Genre:     found.Genres,
+		CoverURL:  found.CoverURL,
+	}
+	if mediaType != "" {
+		req.MediaType = mediaType
+	}
+	if req.MediaType == "" {
+		return nil, ErrUnknownMediaType
+	}
+	if found.ReleaseYear > 0 {
+		yr := found.ReleaseYear
+		req.ReleaseYear = &yr
+	}
+
+	if req.Creator == "" {
+		matches, err := s.Search(ctx, req.Title, req.MediaType, req.ReleaseYear)
+		if err != nil {
+			slog.Warn("code lookup enrichment failed", "code", code, "error", err)
+		} else if len(matches) > 0 {
+			m := matches[0]
+			req.Creator = m.Creator
+			if len(req.Genre) == 0 {
+				req.Genre = m.Genres
+			}
+			if req.CoverURL == "" {
+				req.CoverURL = m.CoverURL
+			}
+			if req.ReleaseYear == nil && m.ReleaseYear > 0 {
+				yr := m.ReleaseYear
+				req.ReleaseYear = &yr
+			}
+		}
+	}
+	if req.Genre == nil {
+		req.Genre = []string{}
+	}
+	return req, nil
 }
@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+type staticProvider []*Result
+
+func (p staticProvider) Search(context.Context, string, *int) ([]*Result, error) { return p, nil }
+
+func (p staticProvider) GetByID(context.Context, string) (*Result, error) { return nil, ErrNotFound }
+
+func TestSearchKeepsRequestedType(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("retail", staticProvider{
+		{Title: "Unknown Box"},
+		{Title: "Heat", MediaType: media.MediaTypeMusic},
+	}, 2, media.MediaTypeMovie)
+	registry.Register("films", staticProvider{
+		{Title: "Heat", MediaType: media.MediaTypeMovie},
+	}, 1, media.MediaTypeMovie)
+
+	results, err := NewService(registry).Search(context.Background(), "heat", media.MediaTypeMovie, nil)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(results) != 1 || results[0].Provider != "films" || results[0].MediaType != media.MediaTypeMovie {
+		t.Errorf("Search = %+v, want the movie from the fallback provider", results)
+	}
+}
+
+type codeLookupFunc func(ctx context.Context, code string) (*Result, error)
+
+func (f codeLookupFunc) LookupCode(ctx context.Context, code string) (*Result, error) {
+	return f(ctx, code)
+}
+
+func lookupReturning(r *Result, err error) CodeLookup {
+	return codeLookupFunc(func(context.Context, string) (*Result, error) { return r, err })
+}
+
+func TestLookupCode(t *testing.T) {
+	const code = "4006381333931"
+	miss := lookupReturning(nil, fmt.Errorf("product %w", ErrNotFound))
+	outage := lookupReturning(nil, &StatusError{Provider: "test", StatusCode: 503})
+	untyped := lookupReturning(&Result{Title: "Mystery Box"}, nil)
+
+	tests := []struct {
+		name      string
+		lookups   []CodeLookup
+		mediaType media.MediaType
+		wantType  media.MediaType
+		wantErr   func(error) bool
+	}{
+		{
+			name:    "every lookup misses",
+			lookups: []CodeLookup{miss, miss},
+			wantErr: func(err error) bool { return errors.Is(err, ErrNotFound) },
+		},
+		{
+			name:    "a failed lookup is not a miss",
+			lookups: []CodeLookup{outage, miss},
+			wantErr: func(err error) bool { return err != nil && !errors.Is(err, ErrNotFound) },
+		},
+		{
+			name:    "unknown category without a type",
+			lookups: []CodeLookup{untyped},
+			wantErr: func(err error) bool { return errors.Is(err, ErrUnknownMediaType) },
+		},
+		{
+			name:      "unknown category with a type",
+			lookups:   []CodeLookup{miss, untyped},
+			mediaType: media.MediaTypeGame,
+			wantType:  media.MediaTypeGame,
+		},
+		{
+			name:      "type overrides the guess",
+			lookups:   []CodeLookup{lookupReturning(&Result{Title: "Heat", MediaType: media.MediaTypeMusic}, nil)},
+			mediaType: media.MediaTypeMovie,
+			wantType:  media.MediaTypeMovie,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(NewRegistry(), tt.lookups...)
+			req, err := svc.LookupCode(context.Background(), code, tt.mediaType)
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Fatalf("LookupCode error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupCode error: %v", err)
+			}
+			if req.MediaType != tt.wantType {
+				t.Errorf("MediaType = %q, want %q", req.MediaType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestSearchKeepsTMDBResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/movie" || r.URL.Query().Get("query") != "heat" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"results":[{"id":949,"title":"Heat","release_date":"1995-12-15"}]}`) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	tmdb := NewTMDBClient("key", ClientConfig{})
+	tmdb.baseURL = srv.URL
+	registry := NewRegistry()
+	registry.Register(ProviderTMDB, tmdb, 1, media.MediaTypeMovie)
+
+	results, reg, err := NewService(registry).search(context.Background(), "heat", media.MediaTypeMovie, nil)
+	if err != nil {
+		t.Fatalf("search error: %v", err)
+	}
+	if reg.Name != ProviderTMDB || len(results) != 1 || results[0].ExternalID != "949" || results[0].MediaType != media.MediaTypeMovie {
+		t.Errorf("search = %+v from %q, want the TMDB movie", results, reg.Name)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+
+	"github.com/your-org/ems/internal/media"
 )
 
 // TMDBClient fetches movie metadata from The Movie Database.
@@ -16,11 +18,11 @@ type TMDBClient struct {
 	baseURL    string
 }
 
-// NewTMDBClient creates a new TMDB metadata client.
-func NewTMDBClient(apiKey string) *TMDBClient {
+// NewTMDBClient creates a new TMDB metadata client configured by cfg.
+func NewTMDBClient(apiKey string, cfg ClientConfig) *TMDBClient {
 	return &TMDBClient{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		httpClient: newHTTPClient(ProviderTMDB, cfg),
 		baseURL:    "https://api.themoviedb.org/3",
 	}
 }
@@ -42,6 +44,7 @@ type tmdbMovieDetail struct {
 	Overview    string `json:"overview"`
 	ReleaseDate string `json:"release_date"`
 	PosterPath  string `json:"poster_path"`
+	Runtime     int    `json:"runtime"`
 	Genres      []struct {
 		Name string `json:"name"`
 	} `json:"genres"`
@@ -93,9 +96,11 @@ func (c *TMDBClient) Search(ctx context.Context, title string, year *int) ([]*Re
 		}
 		results = append(results, &Result{
 			ExternalID:  strconv.Itoa(r.ID),
+			MediaType:   media.MediaTypeMovie,
 			Title:       r.Title,
 			CoverURL:    coverURL,
 			ReleaseYear: yr,
+			ReleaseDate: fullDate(r.ReleaseDate),
 			Overview:    r.Overview,
 		})
 	}
@@ -110,7 +115,7 @@ func (c *TMDBClient) GetByID(ctx context.Context, id string) (*Result, error) {
 
 	params := url.Values{"api_key": {c.apiKey}, "append_to_response": {"credits"}}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		c.baseURL+"/movie/"+id+"?"+params.Encode(), nil)
+		c.baseURL+"/movie/"+url.PathEscape(id)+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
@@ -147,12 +152,15 @@ func (c *TMDBClient) GetByID(ctx context.Context, id string) (*Result, error) {
 	}
 
 	return &Result{
-		ExternalID:  id,
-		Title:       movie.Title,
-		Creator:     creator,
-		Genres:      genres,
-		CoverURL:    coverURL,
-		ReleaseYear: yr,
-		Overview:    movie.Overview,
+		ExternalID:     id,
+		Title:          movie.Title,
+		Creator:        creator,
+		Genres:         genres,
+		CoverURL:       coverURL,
+		ReleaseYear:    yr,
+		ReleaseDate:    fullDate(movie.ReleaseDate),
+		Overview:       movie.Overview,
+		MediaType:      media.MediaTypeMovie,
+		RuntimeMinutes: movie.Runtime,
 	}, nil
 }
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Retry backoff bounds. The delay doubles per attempt and is jittered down
+// by up to half.
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 4 * time.Second
+)
+
+// ClientConfig controls how a provider client talks to its API.
+type ClientConfig struct {
+	// RateLimit is the requests per second allowed; 0 means unlimited.
+	RateLimit float64
+	// Timeout bounds a whole call, including rate limit waits and retries.
+	Timeout time.Duration
+	// MaxRetries is how often a transient failure is retried.
+	MaxRetries int
+	// BreakerThreshold consecutive failed calls open the circuit breaker for
+	// BreakerCooldown; 0 disables it.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// newHTTPClient returns an HTTP client for a provider. Requests are rate
+// limited and retried on transient failures, and non-2xx responses are
+// returned as a *StatusError. A circuit breaker fails calls fast with a
+// *CircuitOpenError while the provider is down.
+func newHTTPClient(provider string, cfg ClientConfig) *http.Client {
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &providerTransport{
+			provider:   provider,
+			maxRetries: cfg.MaxRetries,
+			breaker:    NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+			next: &rateLimitedTransport{
+				provider: provider,
+				limiter:  NewRateLimiter(cfg.RateLimit),
+				next:     http.DefaultTransport,
+			},
+		},
+	}
+}
+
+// providerTransport adds status checking, retries and circuit breaking.
+type providerTransport struct {
+	provider   string
+	maxRetries int
+	breaker    *CircuitBreaker
+	next       http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ok, wait := t.breaker.Allow(); !ok {
+		if req.Body != nil {
+			req.Body.Close() //nolint:errcheck
+		}
+		return nil, &CircuitOpenError{Provider: t.provider, RetryIn: wait}
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					t.breaker.Release()
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err == nil && resp.StatusCode >= http.StatusBadRequest {
+			err = newStatusError(t.provider, resp)
+		}
+		if err == nil {
+			t.breaker.Record(false)
+			return resp, nil
+		}
+
+		if ctx.Err() != nil {
+			t.breaker.Release()
+			return nil, err
+		}
+		transient := isTransient(err)
+		replayable := req.Body == nil || req.GetBody != nil
+		if !transient || !replayable || attempt >= t.maxRetries {
+			var rateErr *RateLimitError
+			if errors.As(err, &rateErr) {
+				// Being throttled says nothing about whether the provider
+				// is up, so it neither opens nor closes the breaker.
+				t.breaker.Release()
+			} else {
+				t.breaker.Record(transient)
+			}
+			return nil, err
+		}
+
+		timer := time.NewTimer(retryDelay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.breaker.Release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isTransient reports whether a failed request may succeed if repeated.
+// Responses other than 408, 429 and 5xx are final.
+func isTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	// Rate limit rejections and network errors such as resets.
+	return true
+}
+
+// retryDelay is the jittered exponential backoff before retry attempt+1.
+func retryDelay(attempt int) time.Duration {
+	d := retryBaseDelay << attempt
+	if d > retryMaxDelay || d <= 0 {
+		d = retryMaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
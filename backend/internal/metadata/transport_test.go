@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// respond returns a transport answering every request with status.
+func respond(status int, header http.Header) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+}
+
+func newTestTransport(next http.RoundTripper) *providerTransport {
+	return &providerTransport{
+		provider: "test",
+		breaker:  NewCircuitBreaker(1, time.Hour),
+		next: &rateLimitedTransport{
+			provider: "test",
+			limiter:  NewRateLimiter(0),
+			next:     next,
+		},
+	}
+}
+
+func TestProviderTransportRateLimitKeepsBreakerClosed(t *testing.T) {
+	tr := newTestTransport(respond(http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}))
+	req, _ := http.NewRequest(http.MethodGet, "http://provider.test/", nil)
+
+	_, err := tr.RoundTrip(req)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("RoundTrip error = %v, want *RateLimitError", err)
+	}
+	if ok, _ := tr.breaker.Allow(); !ok {
+		t.Error("rate limit response opened the circuit breaker")
+	}
+}
+
+func TestProviderTransportServerErrorOpensBreaker(t *testing.T) {
+	tr := newTestTransport(respond(http.StatusBadGateway, nil))
+	req, _ := http.NewRequest(http.MethodGet, "http://provider.test/", nil)
+
+	_, err := tr.RoundTrip(req)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("RoundTrip error = %v, want a 502 *StatusError", err)
+	}
+	if ok, _ := tr.breaker.Allow(); ok {
+		t.Error("server error left the circuit breaker closed")
+	}
+
+	_, err = tr.RoundTrip(req)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Errorf("RoundTrip error = %v, want *CircuitOpenError", err)
+	}
+}
+
+func TestProviderTransportNotFoundKeepsBreakerClosed(t *testing.T) {
+	tr := newTestTransport(respond(http.StatusNotFound, nil))
+	req, _ := http.NewRequest(http.MethodGet, "http://provider.test/", nil)
+
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("RoundTrip error = %v, want ErrNotFound", err)
+	}
+	if ok, _ := tr.breaker.Allow(); !ok {
+		t.Error("404 response opened the circuit breaker")
+	}
+}
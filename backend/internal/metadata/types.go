@@ -1,18 +1,28 @@
 // Package metadata provides external API metadata enrichment.
 package metadata
 
-import "context"
+import (
+	"context"
+
+	"github.com/your-org/ems/internal/media"
+)
 
 // Result holds normalized metadata from external providers.
 type Result struct {
-	ExternalID  string         `json:"external_id"`
-	Title       string         `json:"title"`
-	Creator     string         `json:"creator"`
-	Genres      []string       `json:"genres"`
-	CoverURL    string         `json:"cover_url"`
-	ReleaseYear int            `json:"release_year"`
-	Overview    string         `json:"overview"`
-	Raw         map[string]any `json:"raw,omitempty"`
+	ExternalID        string          `json:"external_id"`
+	Title             string          `json:"title"`
+	Creator           string          `json:"creator"`
+	Genres            []string        `json:"genres"`
+	CoverURL          string          `json:"cover_url"`
+	ReleaseYear       int             `json:"release_year"`
+	ReleaseDate       string          `json:"release_date,omitempty"`
+	Overview          string          `json:"overview"`
+	RuntimeMinutes    int             `json:"runtime_minutes,omitempty"`
+	DurationMinutes   int             `json:"duration_minutes,omitempty"`
+	TimeToBeatMinutes int             `json:"time_to_beat_minutes,omitempty"`
+	MediaType         media.MediaType `json:"media_type,omitempty"`
+	Provider          string          `json:"provider,omitempty"`
+	Raw               map[string]any  `json:"raw,omitempty"`
 }
 
 // Provider is the interface for metadata providers.
@@ -20,3 +30,9 @@ type Provider interface {
 	Search(ctx context.Context, title string, year *int) ([]*Result, error)
 	GetByID(ctx context.Context, id string) (*Result, error)
 }
+
+// CodeLookup is implemented by providers that can resolve a product barcode
+// (UPC-A, EAN-8, EAN-13) or ISBN to a single result.
+type CodeLookup interface {
+	LookupCode(ctx context.Context, code string) (*Result, error)
+}
@@ -0,0 +1,126 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// UPCItemDBClient resolves retail barcodes through UPCitemdb. Without an API
+// key it uses the rate-limited trial endpoint.
+type UPCItemDBClient struct {
+	apiKey     string
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewUPCItemDBClient creates a new UPCitemdb client configured by cfg.
+func NewUPCItemDBClient(apiKey string, cfg ClientConfig) *UPCItemDBClient {
+	baseURL := "https://api.upcitemdb.com/prod/trial"
+	if apiKey != "" {
+		baseURL = "https://api.upcitemdb.com/prod/v1"
+	}
+	return &UPCItemDBClient{
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(ProviderUPCItemDB, cfg),
+		baseURL:    baseURL,
+	}
+}
+
+type upcResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Items   []struct {
+		EAN         string   `json:"ean"`
+		UPC         string   `json:"upc"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Brand       string   `json:"brand"`
+		Category    string   `json:"category"`
+		Images      []string `json:"images"`
+	} `json:"items"`
+}
+
+func (c *UPCItemDBClient) get(ctx context.Context, path string, params url.Values) (*upcResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("user_key", c.apiKey)
+		req.Header.Set("key_type", "3scale")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upcitemdb request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	var data upcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode upcitemdb response: %w", err)
+	}
+	if data.Code != "OK" {
+		return nil, fmt.Errorf("upcitemdb: %s", data.Message)
+	}
+	return &data, nil
+}
+
+func (c *UPCItemDBClient) results(data *upcResponse) []*Result {
+	results := make([]*Result, 0, len(data.Items))
+	for _, it := range data.Items {
+		id := it.EAN
+		if id == "" {
+			id = it.UPC
+		}
+		coverURL := ""
+		if len(it.Images) > 0 {
+			coverURL = it.Images[0]
+		}
+		results = append(results, &Result{
+			ExternalID: id,
+			Title:      cleanProductTitle(it.Title),
+			CoverURL:   coverURL,
+			Overview:   it.Description,
+			MediaType:  mediaTypeFromCategory(it.Category),
+			Raw: map[string]any{
+				"product_title": it.Title,
+				"brand":         it.Brand,
+				"category":      it.Category,
+			},
+		})
+	}
+	return results
+}
+
+// Search searches for products by title.
+func (c *UPCItemDBClient) Search(ctx context.Context, title string, year *int) ([]*Result, error) {
+	data, err := c.get(ctx, "/search", url.Values{"s": {title}, "match_mode": {"0"}, "type": {"product"}})
+	if err != nil {
+		return nil, err
+	}
+	return c.results(data), nil
+}
+
+// GetByID fetches a product by UPC or EAN.
+func (c *UPCItemDBClient) GetByID(ctx context.Context, id string) (*Result, error) {
+	return c.LookupCode(ctx, id)
+}
+
+// LookupCode resolves a UPC, EAN or ISBN-13 to a product.
+func (c *UPCItemDBClient) LookupCode(ctx context.Context, code string) (*Result, error) {
+	data, err := c.get(ctx, "/lookup", url.Values{"upc": {code}})
+	if err != nil {
+		return nil, err
+	}
+	results := c.results(data)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("product %w", ErrNotFound)
+	}
+	return results[0], nil
+}
@@ -7,28 +7,37 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/your-org/ems/internal/auth"
 	"github.com/your-org/ems/internal/httputil"
 	"github.com/your-org/ems/internal/media"
+	"github.com/your-org/ems/internal/review"
 )
 
 // Handler handles HTTP requests for profile endpoints.
 type Handler struct {
-	db        *pgxpool.Pool
-	mediaRepo *media.Repository
+	db         *pgxpool.Pool
+	mediaRepo  *media.Repository
+	reviewRepo *review.Repository
 }
 
 // NewHandler creates a new profile Handler.
-func NewHandler(db *pgxpool.Pool, mediaRepo *media.Repository) *Handler {
-	return &Handler{db: db, mediaRepo: mediaRepo}
+func NewHandler(db *pgxpool.Pool, mediaRepo *media.Repository, reviewRepo *review.Repository) *Handler {
+	return &Handler{db: db, mediaRepo: mediaRepo, reviewRepo: reviewRepo}
 }
 
-// GetPublic handles GET /api/profile/:username.
+// GetPublic handles GET /api/profile/:username. Authentication is optional;
+// a signed-in follower also sees followers-only items.
 func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
 	username := chi.URLParam(r, "username")
 
+	var viewerID *uuid.UUID
+	if claims := auth.ClaimsFromCtx(r.Context()); claims != nil {
+		viewerID = &claims.UserID
+	}
+
 	var profile Profile
 	err := h.db.QueryRow(r.Context(), `
 		SELECT id, username, display_name, bio, avatar_url, is_public, created_at
@@ -46,15 +55,19 @@ func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !profile.IsPublic {
+	isOwner := viewerID != nil && *viewerID == profile.ID
+	if !profile.IsPublic && !isOwner {
 		httputil.WriteError(w, http.StatusForbidden, "profile is private")
 		return
 	}
 
-	items, _, err := h.mediaRepo.List(r.Context(), media.ListFilter{
-		UserID:   profile.ID,
-		PageSize: 100,
-	})
+	items, err := h.mediaRepo.ListPublic(r.Context(), profile.ID, viewerID, 100)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reviews, err := h.reviewRepo.ListPublished(r.Context(), profile.ID, viewerID, 50)
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -63,6 +76,7 @@ func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusOK, map[string]any{
 		"profile": profile,
 		"items":   items,
+		"reviews": reviews,
 	})
 }
 
@@ -100,6 +114,15 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		args = append(args, *req.IsPublic)
 		argIdx++
 	}
+	if req.DefaultVisibility != nil {
+		if !req.DefaultVisibility.Valid() {
+			httputil.WriteError(w, http.StatusBadRequest, "default_visibility must be public, followers or private")
+			return
+		}
+		sets = append(sets, fmt.Sprintf("default_visibility=$%d", argIdx))
+		args = append(args, *req.DefaultVisibility)
+		argIdx++
+	}
 
 	if len(sets) == 0 {
 		httputil.WriteError(w, http.StatusBadRequest, "no fields to update")
@@ -110,12 +133,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	var profile Profile
 	err := h.db.QueryRow(r.Context(),
 		fmt.Sprintf(`UPDATE users SET %s WHERE id=$%d
-			RETURNING id, username, display_name, bio, avatar_url, is_public, created_at`,
+			RETURNING id, username, display_name, bio, avatar_url, is_public, created_at, default_visibility`,
 			strings.Join(sets, ","), argIdx),
 		args...,
 	).Scan(
 		&profile.ID, &profile.Username, &profile.DisplayName,
 		&profile.Bio, &profile.AvatarURL, &profile.IsPublic, &profile.CreatedAt,
+		&profile.DefaultVisibility,
 	)
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
@@ -131,11 +155,12 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	var profile Profile
 	err := h.db.QueryRow(r.Context(), `
-		SELECT id, username, display_name, bio, avatar_url, is_public, created_at
+		SELECT id, username, display_name, bio, avatar_url, is_public, created_at, default_visibility
 		FROM users WHERE id = $1
 	`, claims.UserID).Scan(
 		&profile.ID, &profile.Username, &profile.DisplayName,
 		&profile.Bio, &profile.AvatarURL, &profile.IsPublic, &profile.CreatedAt,
+		&profile.DefaultVisibility,
 	)
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
@@ -143,3 +168,116 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 	}
 	httputil.WriteJSON(w, http.StatusOK, profile)
 }
+
+// Follow handles POST /api/profile/:username/follow. It sends a follow
+// request, which takes effect once the followed user accepts it.
+func (h *Handler) Follow(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	result, err := h.db.Exec(r.Context(), `
+		INSERT INTO follows (follower_id, followee_id)
+		SELECT $1, id FROM users WHERE username = $2 AND id <> $1
+		ON CONFLICT DO NOTHING
+	`, claims.UserID, chi.URLParam(r, "username"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := h.db.QueryRow(r.Context(), `
+			SELECT EXISTS (SELECT 1 FROM users WHERE username = $1 AND id <> $2)
+		`, chi.URLParam(r, "username"), claims.UserID).Scan(&exists); err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !exists {
+			httputil.WriteError(w, http.StatusNotFound, "profile not found")
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unfollow handles DELETE /api/profile/:username/follow.
+func (h *Handler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	_, err := h.db.Exec(r.Context(), `
+		DELETE FROM follows
+		WHERE follower_id = $1 AND followee_id = (SELECT id FROM users WHERE username = $2)
+	`, claims.UserID, chi.URLParam(r, "username"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Followers handles GET /api/profile/me/followers, listing pending follow
+// requests first.
+func (h *Handler) Followers(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	rows, err := h.db.Query(r.Context(), `
+		SELECT u.username, u.display_name, u.avatar_url, f.accepted, f.created_at, f.accepted_at
+		FROM follows f JOIN users u ON u.id = f.follower_id
+		WHERE f.followee_id = $1
+		ORDER BY f.accepted, f.created_at DESC
+	`, claims.UserID)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	followers := []Follower{}
+	for rows.Next() {
+		var f Follower
+		if err := rows.Scan(&f.Username, &f.DisplayName, &f.AvatarURL, &f.Accepted, &f.RequestedAt, &f.AcceptedAt); err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		followers = append(followers, f)
+	}
+	if err := rows.Err(); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, followers)
+}
+
+// AcceptFollower handles PUT /api/profile/me/followers/:username.
+func (h *Handler) AcceptFollower(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	result, err := h.db.Exec(r.Context(), `
+		UPDATE follows SET accepted = true, accepted_at = COALESCE(accepted_at, now())
+		WHERE followee_id = $1 AND follower_id = (SELECT id FROM users WHERE username = $2)
+	`, claims.UserID, chi.URLParam(r, "username"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if result.RowsAffected() == 0 {
+		httputil.WriteError(w, http.StatusNotFound, "follow request not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFollower handles DELETE /api/profile/me/followers/:username. It
+// declines a pending request or removes an accepted follower.
+func (h *Handler) RemoveFollower(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	_, err := h.db.Exec(r.Context(), `
+		DELETE FROM follows
+		WHERE followee_id = $1 AND follower_id = (SELECT id FROM users WHERE username = $2)
+	`, claims.UserID, chi.URLParam(r, "username"))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,23 +5,38 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/your-org/ems/internal/media"
 )
 
-// Profile is the public-facing user profile.
+// Profile is the public-facing user profile. DefaultVisibility is only
+// reported to the profile's owner.
 type Profile struct {
-	ID          uuid.UUID `json:"id"`
-	Username    string    `json:"username"`
-	DisplayName string    `json:"display_name"`
-	Bio         string    `json:"bio"`
-	AvatarURL   string    `json:"avatar_url"`
-	IsPublic    bool      `json:"is_public"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                uuid.UUID        `json:"id"`
+	Username          string           `json:"username"`
+	DisplayName       string           `json:"display_name"`
+	Bio               string           `json:"bio"`
+	AvatarURL         string           `json:"avatar_url"`
+	IsPublic          bool             `json:"is_public"`
+	CreatedAt         time.Time        `json:"created_at"`
+	DefaultVisibility media.Visibility `json:"default_visibility,omitempty"`
 }
 
 // UpdateRequest holds profile update fields.
 type UpdateRequest struct {
-	DisplayName *string `json:"display_name,omitempty"`
-	Bio         *string `json:"bio,omitempty"`
-	AvatarURL   *string `json:"avatar_url,omitempty"`
-	IsPublic    *bool   `json:"is_public,omitempty"`
+	DisplayName       *string           `json:"display_name,omitempty"`
+	Bio               *string           `json:"bio,omitempty"`
+	AvatarURL         *string           `json:"avatar_url,omitempty"`
+	IsPublic          *bool             `json:"is_public,omitempty"`
+	DefaultVisibility *media.Visibility `json:"default_visibility,omitempty"`
+}
+
+// Follower is a user following, or asking to follow, the signed-in user.
+// Only accepted followers see followers-only items.
+type Follower struct {
+	Username    string     `json:"username"`
+	DisplayName string     `json:"display_name"`
+	AvatarURL   string     `json:"avatar_url"`
+	Accepted    bool       `json:"accepted"`
+	RequestedAt time.Time  `json:"requested_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
 }
@@ -0,0 +1,29 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: body is required", ErrInvalidReview), http.StatusBadRequest},
+		{fmt.Errorf("item %w", ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("review %w", ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("upsert review: %w", errors.New("connection reset")), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeError(rec, tt.err)
+		if rec.Code != tt.want {
+			t.Errorf("writeError(%q) status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+}
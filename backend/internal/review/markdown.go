@@ -0,0 +1,324 @@
+package review
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rendered is the sanitized output of a review body.
+type Rendered struct {
+	HTML        string
+	SearchText  string
+	HasSpoilers bool
+}
+
+var (
+	headingRe     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	orderedItemRe = regexp.MustCompile(`^\d{1,9}[.)]\s+`)
+	hruleRe       = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+)
+
+// Render converts review markdown into HTML. Raw HTML in the source is always
+// escaped; only the tags produced by the renderer itself are emitted, and link
+// targets are restricted to http, https and mailto.
+//
+// Spoilers are marked either as a block fenced by ":::spoiler" and ":::" lines
+// or inline with ||double bars||. Spoiler content is excluded from SearchText.
+func Render(src string) Rendered {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	r := &renderer{}
+	r.blocks(strings.Split(src, "\n"))
+	return Rendered{
+		HTML:        strings.TrimSpace(r.html.String()),
+		SearchText:  strings.Join(strings.Fields(r.text.String()), " "),
+		HasSpoilers: r.hasSpoilers,
+	}
+}
+
+type renderer struct {
+	html        strings.Builder
+	text        strings.Builder
+	spoiler     int
+	hasSpoilers bool
+}
+
+// writeText records plain text for search indexing unless inside a spoiler.
+func (r *renderer) writeText(s string) {
+	if r.spoiler == 0 {
+		r.text.WriteString(s)
+	}
+}
+
+func (r *renderer) blocks(lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, ":::spoiler"):
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != ":::" {
+				end++
+			}
+			summary := strings.TrimSpace(strings.TrimPrefix(trimmed, ":::spoiler"))
+			if summary == "" {
+				summary = "Spoiler"
+			}
+			r.hasSpoilers = true
+			r.spoiler++
+			r.html.WriteString(`<details class="spoiler"><summary>`)
+			r.html.WriteString(escapeHTML(summary))
+			r.html.WriteString("</summary>\n")
+			r.blocks(lines[i+1 : end])
+			r.html.WriteString("</details>\n")
+			r.spoiler--
+			i = end + 1
+
+		case strings.HasPrefix(trimmed, "```"):
+			end := i + 1
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "```") {
+				end++
+			}
+			code := strings.Join(lines[i+1:end], "\n")
+			r.html.WriteString("<pre><code>")
+			r.html.WriteString(escapeHTML(code))
+			r.html.WriteString("</code></pre>\n")
+			r.writeText(code + "\n")
+			i = end + 1
+
+		case headingRe.MatchString(trimmed):
+			m := headingRe.FindStringSubmatch(trimmed)
+			tag := "h" + strconv.Itoa(len(m[1]))
+			r.html.WriteString("<" + tag + ">")
+			r.inline(m[2])
+			r.html.WriteString("</" + tag + ">\n")
+			r.writeText("\n")
+			i++
+
+		case hruleRe.MatchString(trimmed):
+			r.html.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+				i++
+			}
+			r.html.WriteString("<blockquote>\n")
+			r.blocks(quoted)
+			r.html.WriteString("</blockquote>\n")
+
+		case isBulletItem(trimmed):
+			r.html.WriteString("<ul>\n")
+			for i < len(lines) && isBulletItem(strings.TrimSpace(lines[i])) {
+				r.html.WriteString("<li>")
+				r.inline(strings.TrimSpace(lines[i])[2:])
+				r.html.WriteString("</li>\n")
+				r.writeText("\n")
+				i++
+			}
+			r.html.WriteString("</ul>\n")
+
+		case orderedItemRe.MatchString(trimmed):
+			r.html.WriteString("<ol>\n")
+			for i < len(lines) && orderedItemRe.MatchString(strings.TrimSpace(lines[i])) {
+				item := strings.TrimSpace(lines[i])
+				r.html.WriteString("<li>")
+				r.inline(item[len(orderedItemRe.FindString(item)):])
+				r.html.WriteString("</li>\n")
+				r.writeText("\n")
+				i++
+			}
+			r.html.WriteString("</ol>\n")
+
+		default:
+			var para []string
+			for i < len(lines) && !startsBlock(lines[i]) {
+				para = append(para, strings.TrimSpace(lines[i]))
+				i++
+			}
+			if len(para) == 0 {
+				// A line that looks like a block start but did not match above.
+				para = append(para, trimmed)
+				i++
+			}
+			r.html.WriteString("<p>")
+			r.inline(strings.Join(para, "\n"))
+			r.html.WriteString("</p>\n")
+			r.writeText("\n")
+		}
+	}
+}
+
+func isBulletItem(s string) bool {
+	return len(s) > 2 && (s[0] == '-' || s[0] == '*' || s[0] == '+') && s[1] == ' '
+}
+
+// startsBlock reports whether line ends the current paragraph.
+func startsBlock(line string) bool {
+	t := strings.TrimSpace(line)
+	return t == "" ||
+		strings.HasPrefix(t, ":::spoiler") ||
+		strings.HasPrefix(t, "```") ||
+		strings.HasPrefix(t, ">") ||
+		headingRe.MatchString(t) ||
+		hruleRe.MatchString(t) ||
+		isBulletItem(t) ||
+		orderedItemRe.MatchString(t)
+}
+
+// inline renders emphasis, code spans, links and inline spoilers.
+func (r *renderer) inline(s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		rest := s[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()#+-.!|~>", s[i+1]) >= 0:
+			r.html.WriteString(escapeHTML(s[i+1 : i+2]))
+			r.writeText(s[i+1 : i+2])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				code := rest[1 : end+1]
+				r.html.WriteString("<code>" + escapeHTML(code) + "</code>")
+				r.writeText(code)
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(rest, "||"):
+			if end := strings.Index(rest[2:], "||"); end > 0 {
+				r.hasSpoilers = true
+				r.spoiler++
+				r.html.WriteString(`<span class="spoiler">`)
+				r.inline(rest[2 : end+2])
+				r.html.WriteString("</span>")
+				r.spoiler--
+				i += end + 4
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if n := r.wrap(rest, rest[:2], "strong"); n > 0 {
+				i += n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if n := r.wrap(rest, "~~", "del"); n > 0 {
+				i += n
+				continue
+			}
+
+		case c == '*' || (c == '_' && (i == 0 || !isWordByte(s[i-1]))):
+			if n := r.wrap(rest, rest[:1], "em"); n > 0 {
+				i += n
+				continue
+			}
+
+		case c == '[':
+			if n := r.link(rest); n > 0 {
+				i += n
+				continue
+			}
+
+		case c == '\n':
+			r.html.WriteString("<br>\n")
+			r.writeText(" ")
+			i++
+			continue
+		}
+
+		r.html.WriteString(escapeHTML(s[i : i+1]))
+		r.writeText(s[i : i+1])
+		i++
+	}
+}
+
+// wrap renders delim-enclosed text at the start of s inside tag and returns
+// the number of bytes consumed, or 0 if there is no closing delimiter. When
+// the text opens with more of the delimiter, as in ***both***, the closing
+// delimiter is the end of the run so the nested emphasis closes inside.
+func (r *renderer) wrap(s, delim, tag string) int {
+	body := s[len(delim):]
+	end := strings.Index(body, delim)
+	if end <= 0 || strings.TrimSpace(body[:end]) == "" {
+		return 0
+	}
+	if body[0] == delim[0] {
+		for end+len(delim) < len(body) && body[end+len(delim)] == delim[0] {
+			end++
+		}
+	}
+	r.html.WriteString("<" + tag + ">")
+	r.inline(body[:end])
+	r.html.WriteString("</" + tag + ">")
+	return len(delim)*2 + end
+}
+
+// link renders a [text](url) link at the start of s and returns the number of
+// bytes consumed, or 0 if s does not start with a link.
+func (r *renderer) link(s string) int {
+	closeText := strings.Index(s, "](")
+	if closeText <= 0 {
+		return 0
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 0 {
+		return 0
+	}
+	text := s[1:closeText]
+	target := strings.TrimSpace(s[closeText+2 : closeText+2+closeURL])
+
+	if safeURL(target) {
+		r.html.WriteString(`<a href="` + escapeHTML(target) + `" rel="nofollow noopener noreferrer">`)
+		r.inline(text)
+		r.html.WriteString("</a>")
+	} else {
+		r.inline(text)
+	}
+	return closeText + 2 + closeURL + 1
+}
+
+func safeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	default:
+		return false
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+var htmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#39;",
+)
+
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,149 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		html string
+		text string
+	}{
+		{
+			name: "raw html is escaped",
+			src:  "<script>alert(1)</script>",
+			html: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+			text: "<script>alert(1)</script>",
+		},
+		{
+			name: "javascript link",
+			src:  "[x](javascript:alert(1))",
+			html: "<p>x)</p>",
+			text: "x)",
+		},
+		{
+			name: "mixed case javascript link",
+			src:  "[x](JaVaScRiPt:alert(1))",
+			html: "<p>x)</p>",
+			text: "x)",
+		},
+		{
+			name: "data link",
+			src:  "[x](data:text/html,<script>)",
+			html: "<p>x</p>",
+			text: "x",
+		},
+		{
+			name: "scheme-relative link",
+			src:  "[x](//evil.example)",
+			html: "<p>x</p>",
+			text: "x",
+		},
+		{
+			name: "quotes in link target",
+			src:  `[x](https://a.example/"onmouseover="alert(1))`,
+			html: `<p><a href="https://a.example/&quot;onmouseover=&quot;alert(1" rel="nofollow noopener noreferrer">x</a>)</p>`,
+			text: "x)",
+		},
+		{
+			name: "markup in link target",
+			src:  "[x](https://a.example/?a=1&b=<2>)",
+			html: `<p><a href="https://a.example/?a=1&amp;b=&lt;2&gt;" rel="nofollow noopener noreferrer">x</a></p>`,
+			text: "x",
+		},
+		{
+			name: "emphasis in mailto link",
+			src:  "[*me*](mailto:me@a.example)",
+			html: `<p><a href="mailto:me@a.example" rel="nofollow noopener noreferrer"><em>me</em></a></p>`,
+			text: "me",
+		},
+		{
+			name: "nested emphasis",
+			src:  "**bold _and em_ here**",
+			html: "<p><strong>bold <em>and em</em> here</strong></p>",
+			text: "bold and em here",
+		},
+		{
+			name: "emphasis opening strong",
+			src:  "***both***",
+			html: "<p><strong><em>both</em></strong></p>",
+			text: "both",
+		},
+		{
+			name: "underscores within words",
+			src:  "snake_case_word",
+			html: "<p>snake_case_word</p>",
+			text: "snake_case_word",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.src)
+			if got.HTML != tt.html {
+				t.Errorf("HTML = %q, want %q", got.HTML, tt.html)
+			}
+			if got.SearchText != tt.text {
+				t.Errorf("SearchText = %q, want %q", got.SearchText, tt.text)
+			}
+			if got.HasSpoilers {
+				t.Error("HasSpoilers = true without spoilers")
+			}
+		})
+	}
+}
+
+func TestRenderSpoilers(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		html string
+		text string
+	}{
+		{
+			name: "block",
+			src:  ":::spoiler Ending\nHe **dies**.\n:::\nVisible text",
+			html: "<details class=\"spoiler\"><summary>Ending</summary>\n<p>He <strong>dies</strong>.</p>\n</details>\n<p>Visible text</p>",
+			text: "Visible text",
+		},
+		{
+			name: "unclosed block",
+			src:  ":::spoiler\nunclosed",
+			html: "<details class=\"spoiler\"><summary>Spoiler</summary>\n<p>unclosed</p>\n</details>",
+			text: "",
+		},
+		{
+			name: "markup in summary",
+			src:  ":::spoiler <img src=x onerror=alert(1)>\nhidden\n:::",
+			html: "<details class=\"spoiler\"><summary>&lt;img src=x onerror=alert(1)&gt;</summary>\n<p>hidden</p>\n</details>",
+			text: "",
+		},
+		{
+			name: "inline",
+			src:  "Before ||the twist|| after",
+			html: `<p>Before <span class="spoiler">the twist</span> after</p>`,
+			text: "Before after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.src)
+			if got.HTML != tt.html {
+				t.Errorf("HTML = %q, want %q", got.HTML, tt.html)
+			}
+			if got.SearchText != tt.text {
+				t.Errorf("SearchText = %q, want %q", got.SearchText, tt.text)
+			}
+			if !got.HasSpoilers {
+				t.Error("HasSpoilers = false")
+			}
+			if strings.Contains(got.SearchText, "twist") || strings.Contains(got.SearchText, "dies") {
+				t.Errorf("SearchText %q leaks spoiler content", got.SearchText)
+			}
+		})
+	}
+}
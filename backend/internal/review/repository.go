@@ -0,0 +1,155 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles database operations for reviews.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new review Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const reviewColumns = `r.id, r.user_id, r.media_item_id, m.title, r.body, r.body_html,
+	r.has_spoilers, r.published, r.published_at, r.created_at, r.updated_at`
+
+func scanReview(row pgx.Row) (*Review, error) {
+	var rv Review
+	err := row.Scan(
+		&rv.ID, &rv.UserID, &rv.MediaItemID, &rv.MediaTitle, &rv.Body, &rv.BodyHTML,
+		&rv.HasSpoilers, &rv.Published, &rv.PublishedAt, &rv.CreatedAt, &rv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+// Upsert creates or replaces the user's review of a media item they own or
+// share through a collection.
+// published_at is set the first time a review is published and cleared when
+// it is unpublished.
+func (r *Repository) Upsert(ctx context.Context, userID, mediaItemID uuid.UUID, body string, rendered Rendered, published bool) (*Review, error) {
+	row := r.db.QueryRow(ctx, `
+		WITH upserted AS (
+			INSERT INTO reviews (user_id, media_item_id, body, body_html, search_text,
+				has_spoilers, published, published_at)
+			SELECT $1, m.id, $3, $4, $5, $6, $7::boolean, CASE WHEN $7::boolean THEN now() END
+			FROM media_items m
+			WHERE m.id = $2 AND ((m.user_id = $1 AND m.collection_id IS NULL) OR m.collection_id IN (
+				SELECT collection_id FROM collection_members WHERE user_id = $1
+			))
+			ON CONFLICT (user_id, media_item_id) DO UPDATE SET
+				body = EXCLUDED.body,
+				body_html = EXCLUDED.body_html,
+				search_text = EXCLUDED.search_text,
+				has_spoilers = EXCLUDED.has_spoilers,
+				published = EXCLUDED.published,
+				published_at = CASE
+					WHEN EXCLUDED.published THEN COALESCE(reviews.published_at, now())
+				END
+			RETURNING *
+		)
+		SELECT `+reviewColumns+`
+		FROM upserted r JOIN media_items m ON m.id = r.media_item_id`,
+		userID, mediaItemID, body, rendered.HTML, rendered.SearchText,
+		rendered.HasSpoilers, published,
+	)
+	rv, err := scanReview(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("item %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("upsert review: %w", err)
+	}
+	return rv, nil
+}
+
+// GetForItem fetches the user's review of a media item.
+func (r *Repository) GetForItem(ctx context.Context, userID, mediaItemID uuid.UUID) (*Review, error) {
+	row := r.db.QueryRow(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews r JOIN media_items m ON m.id = r.media_item_id
+		WHERE r.user_id = $1 AND r.media_item_id = $2`,
+		userID, mediaItemID,
+	)
+	rv, err := scanReview(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("review %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("query review: %w", err)
+	}
+	return rv, nil
+}
+
+// Delete removes the user's review of a media item.
+func (r *Repository) Delete(ctx context.Context, userID, mediaItemID uuid.UUID) error {
+	result, err := r.db.Exec(ctx,
+		"DELETE FROM reviews WHERE user_id=$1 AND media_item_id=$2", userID, mediaItemID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete review: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("review %w", ErrNotFound)
+	}
+	return nil
+}
+
+// ListForUser returns all of a user's reviews, newest first.
+func (r *Repository) ListForUser(ctx context.Context, userID uuid.UUID) ([]*Review, error) {
+	return r.list(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews r JOIN media_items m ON m.id = r.media_item_id
+		WHERE r.user_id = $1
+		ORDER BY r.updated_at DESC`, userID)
+}
+
+// ListPublished returns a user's published reviews of items viewerID may see,
+// most recently published first. Item visibility applies as on the profile:
+// a nil viewer only sees reviews of public items.
+func (r *Repository) ListPublished(ctx context.Context, userID uuid.UUID, viewerID *uuid.UUID, limit int) ([]*Review, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return r.list(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews r JOIN media_items m ON m.id = r.media_item_id
+		WHERE r.user_id = $1 AND r.published AND (
+			m.visibility = 'public'
+			OR r.user_id = $2
+			OR (m.visibility = 'followers' AND EXISTS (
+				SELECT 1 FROM follows f WHERE f.followee_id = $1 AND f.follower_id = $2 AND f.accepted
+			))
+		)
+		ORDER BY r.published_at DESC
+		LIMIT $3`, userID, viewerID, limit)
+}
+
+func (r *Repository) list(ctx context.Context, query string, args ...any) ([]*Review, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]*Review, 0)
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		reviews = append(reviews, rv)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,55 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for reviews and media items that do not exist or
+	// that the user cannot see.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidReview is returned when a review body fails validation.
+	ErrInvalidReview = errors.New("invalid review")
+)
+
+// Service renders and stores reviews.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new review Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Upsert validates and renders the markdown body, then stores the review.
+// Validation errors wrap ErrInvalidReview.
+func (s *Service) Upsert(ctx context.Context, userID, mediaItemID uuid.UUID, req UpsertRequest) (*Review, error) {
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidReview)
+	}
+	if len(req.Body) > maxBodyLength {
+		return nil, fmt.Errorf("%w: body must be at most %d bytes", ErrInvalidReview, maxBodyLength)
+	}
+	return s.repo.Upsert(ctx, userID, mediaItemID, req.Body, Render(req.Body), req.Published)
+}
+
+// Get returns the user's review of a media item.
+func (s *Service) Get(ctx context.Context, userID, mediaItemID uuid.UUID) (*Review, error) {
+	return s.repo.GetForItem(ctx, userID, mediaItemID)
+}
+
+// Delete removes the user's review of a media item.
+func (s *Service) Delete(ctx context.Context, userID, mediaItemID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, mediaItemID)
+}
+
+// List returns all reviews written by a user.
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Review, error) {
+	return s.repo.ListForUser(ctx, userID)
+}
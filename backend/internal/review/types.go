@@ -0,0 +1,32 @@
+// Package review provides markdown reviews of media items with spoiler sections.
+package review
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxBodyLength caps the size of a review body in bytes.
+const maxBodyLength = 50000
+
+// Review is a user's write-up of a single media item.
+type Review struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	MediaItemID uuid.UUID  `json:"media_item_id"`
+	MediaTitle  string     `json:"media_title,omitempty"`
+	Body        string     `json:"body"`
+	BodyHTML    string     `json:"body_html"`
+	HasSpoilers bool       `json:"has_spoilers"`
+	Published   bool       `json:"published"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// UpsertRequest is the payload for creating or replacing a review.
+type UpsertRequest struct {
+	Body      string `json:"body"`
+	Published bool   `json:"published"`
+}
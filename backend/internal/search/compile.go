@@ -0,0 +1,119 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+// Compiled is a Query translated into SQL over the columns of the items a
+// user can see, as media search exposes them.
+type Compiled struct {
+	// Text holds the words of positive text terms, used for full-text
+	// matching, ranking and highlighting.
+	Text string
+	// Where is a predicate using ? placeholders for Args, or empty.
+	Where string
+	Args  []any
+}
+
+// numericColumns maps numeric fields to their columns.
+var numericColumns = map[Field]string{
+	FieldYear:   "release_year",
+	FieldRating: "rating",
+}
+
+// Compile translates the query into a parameterized predicate. Every value
+// from the query is passed as an argument; only fixed SQL is interpolated.
+// Negated terms treat unknown values (such as a missing rating) as not
+// matching, so -rating:<5 keeps unrated items.
+func (q *Query) Compile() Compiled {
+	var c Compiled
+	var text []string
+	var conditions []string
+
+	for _, term := range q.Terms {
+		var cond string
+		var args []any
+
+		switch t := term.(type) {
+		case *TextTerm:
+			if !t.Neg {
+				text = append(text, t.Text)
+				if !t.Phrase {
+					continue
+				}
+			}
+			tsquery := "plainto_tsquery"
+			if t.Phrase {
+				tsquery = "phraseto_tsquery"
+			}
+			// Notes are matched only where the viewer may read them.
+			cond = fmt.Sprintf(
+				"(search_vector @@ %[1]s('english', ?) OR (notes_readable AND notes_vector @@ %[1]s('english', ?))"+
+					" OR title ILIKE ? OR creator ILIKE ?)", tsquery,
+			)
+			pattern := containsPattern(t.Text)
+			args = []any{t.Text, t.Text, pattern, pattern}
+
+		case *FieldTerm:
+			cond, args = compileField(t)
+		}
+
+		if term.Negated() {
+			cond = "NOT COALESCE(" + cond + ", false)"
+		}
+		conditions = append(conditions, cond)
+		c.Args = append(c.Args, args...)
+	}
+
+	c.Text = strings.Join(text, " ")
+	c.Where = strings.Join(conditions, " AND ")
+	return c
+}
+
+func compileField(t *FieldTerm) (string, []any) {
+	switch t.Field {
+	case FieldType:
+		return "media_type::text = ?", []any{t.Value}
+	case FieldStatus:
+		return "status::text = ?", []any{t.Value}
+	case FieldGenre:
+		return "EXISTS (SELECT 1 FROM unnest(genre) AS g WHERE g ILIKE ?)", []any{containsPattern(t.Value)}
+	case FieldCreator:
+		return "creator ILIKE ?", []any{containsPattern(t.Value)}
+	case FieldTitle:
+		return "title ILIKE ?", []any{containsPattern(t.Value)}
+	}
+
+	col := numericColumns[t.Field]
+	if t.Op == OpRange {
+		switch {
+		case t.Low != nil && t.High != nil:
+			return col + " BETWEEN ? AND ?", []any{numberArg(t.Field, *t.Low), numberArg(t.Field, *t.High)}
+		case t.Low != nil:
+			return col + " >= ?", []any{numberArg(t.Field, *t.Low)}
+		default:
+			return col + " <= ?", []any{numberArg(t.Field, *t.High)}
+		}
+	}
+	op := string(t.Op)
+	if t.Op == OpMatch {
+		op = "="
+	}
+	return col + " " + op + " ?", []any{numberArg(t.Field, t.Number)}
+}
+
+// numberArg converts a parsed number to the Go type of the field's column.
+func numberArg(field Field, n float64) any {
+	if field == FieldYear {
+		return int(n)
+	}
+	return n
+}
+
+// containsPattern returns an ILIKE pattern matching s anywhere.
+func containsPattern(s string) string {
+	return "%" + media.EscapeLike(s) + "%"
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/httputil"
+	"github.com/your-org/ems/internal/media"
+)
+
+// maxDiscoverPageSize caps the page size of discovery searches, which scan
+// every public collection.
+const maxDiscoverPageSize = 50
+
+// Discover handles GET /api/search/discover. It matches q against the titles
+// and creators of items on every public profile and groups the matches by
+// title, listing who holds each one and their average rating. Authentication
+// is optional; a signed-in follower also matches followers-only items. type
+// may be repeated or comma-separated.
+func (h *Handler) Discover(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "query parameter 'q' is required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	f := media.DiscoverFilter{
+		Query:    q,
+		Page:     page,
+		PageSize: min(pageSize, maxDiscoverPageSize),
+	}
+	if claims := auth.ClaimsFromCtx(r.Context()); claims != nil {
+		f.ViewerID = &claims.UserID
+	}
+	for _, t := range queryList(r, "type") {
+		f.MediaTypes = append(f.MediaTypes, media.MediaType(t))
+	}
+
+	result, err := h.repo.Discover(r.Context(), f)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"titles": result.Titles,
+		"total":  result.Total,
+		"page":   page,
+		"query":  q,
+	})
+}
@@ -2,27 +2,57 @@
 package search
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/embedding"
 	"github.com/your-org/ems/internal/httputil"
 	"github.com/your-org/ems/internal/media"
+	"github.com/your-org/ems/internal/metadata"
+	"github.com/your-org/ems/internal/searchlog"
 )
 
+// HandlerConfig holds search handler settings.
+type HandlerConfig struct {
+	// Highlight wraps matched terms unless a request overrides it.
+	Highlight media.HighlightMarkers
+	// SuggestTimeout bounds each kind of collection suggestion.
+	SuggestTimeout time.Duration
+	// ExternalSuggestTimeout bounds metadata provider title suggestions.
+	ExternalSuggestTimeout time.Duration
+}
+
 // Handler handles HTTP requests for search.
 type Handler struct {
-	repo *media.Repository
+	repo       *media.Repository
+	meta       *metadata.Service
+	embeddings *embedding.Service
+	searchLog  *searchlog.Repository
+	cfg        HandlerConfig
 }
 
-// NewHandler creates a new search Handler.
-func NewHandler(repo *media.Repository) *Handler {
-	return &Handler{repo: repo}
+// NewHandler creates a new search Handler. meta may be nil, which disables
+// metadata provider suggestions, and embeddings may be nil, which disables
+// the semantic and hybrid modes. Searches are recorded in searchLog.
+func NewHandler(repo *media.Repository, meta *metadata.Service, embeddings *embedding.Service, searchLog *searchlog.Repository, cfg HandlerConfig) *Handler {
+	return &Handler{repo: repo, meta: meta, embeddings: embeddings, searchLog: searchLog, cfg: cfg}
 }
 
-// Search handles GET /api/search.
+// Search handles GET /api/search. The q parameter uses the query language
+// understood by Parse. mode is keyword (the default), semantic or hybrid;
+// the latter two embed the free text of the query and compare it with the
+// item vectors kept current by embedding.RunRefresh. Facet selections are passed as repeated or
+// comma-separated type, status, genre, decade and rating parameters, and the
+// highlight markers may be overridden with a matching hl_start and hl_stop
+// pair from highlightTags.
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	claims := auth.ClaimsFromCtx(r.Context())
+	start := time.Now()
 
 	q := r.URL.Query().Get("q")
 	if q == "" {
@@ -39,22 +69,125 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	var mediaType *media.MediaType
-	if t := r.URL.Query().Get("type"); t != "" {
-		mt := media.MediaType(t)
-		mediaType = &mt
+	parsed, err := Parse(q)
+	if err != nil {
+		var syntaxErr *SyntaxError
+		if errors.As(err, &syntaxErr) {
+			httputil.WriteJSON(w, http.StatusBadRequest, map[string]any{
+				"error":    syntaxErr.Error(),
+				"position": syntaxErr.Pos,
+			})
+			return
+		}
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	compiled := parsed.Compile()
+
+	mode := media.SearchMode(r.URL.Query().Get("mode"))
+	switch mode {
+	case "":
+		mode = media.SearchKeyword
+	case media.SearchKeyword, media.SearchSemantic, media.SearchHybrid:
+	default:
+		httputil.WriteError(w, http.StatusBadRequest, "mode must be keyword, semantic or hybrid")
+		return
 	}
 
-	items, total, err := h.repo.Search(r.Context(), claims.UserID, q, mediaType, page, pageSize)
+	f := media.SearchFilter{
+		UserID:        claims.UserID,
+		Query:         compiled.Text,
+		Mode:          mode,
+		Where:         compiled.Where,
+		WhereArgs:     compiled.Args,
+		Genres:        queryList(r, "genre"),
+		RatingBuckets: queryList(r, "rating"),
+		Highlight:     h.cfg.Highlight,
+		Page:          page,
+		PageSize:      pageSize,
+	}
+	if mode != media.SearchKeyword && compiled.Text != "" {
+		if h.embeddings == nil {
+			httputil.WriteError(w, http.StatusBadRequest, "semantic search is not configured")
+			return
+		}
+		if f.Embedding, err = h.embeddings.EmbedQuery(r.Context(), compiled.Text); err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		f.EmbeddingModel = h.embeddings.Model()
+	}
+	if start, stop := r.URL.Query().Get("hl_start"), r.URL.Query().Get("hl_stop"); start != "" || stop != "" {
+		if f.Highlight, err = highlightMarkers(start, stop); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	for _, t := range queryList(r, "type") {
+		f.MediaTypes = append(f.MediaTypes, media.MediaType(t))
+	}
+	for _, s := range queryList(r, "status") {
+		f.Statuses = append(f.Statuses, media.Status(s))
+	}
+	for _, d := range queryList(r, "decade") {
+		decade, err := strconv.Atoi(strings.TrimSuffix(d, "s"))
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid decade: "+d)
+			return
+		}
+		f.Decades = append(f.Decades, decade/10*10)
+	}
+
+	result, err := h.repo.Search(r.Context(), f)
 	if err != nil {
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if page == 1 {
+		if err := h.searchLog.Record(r.Context(), claims.UserID, searchlog.SourceSearch, q, result.Total, time.Since(start)); err != nil {
+			slog.Warn("record search", "error", err)
+		}
+	}
 
 	httputil.WriteJSON(w, http.StatusOK, map[string]any{
-		"items": items,
-		"total": total,
-		"page":  page,
-		"query": q,
+		"items":  result.Items,
+		"total":  result.Total,
+		"page":   page,
+		"query":  q,
+		"mode":   mode,
+		"facets": result.Facets,
 	})
 }
+
+// highlightTags maps each tag a request may wrap highlights in to the tag
+// closing it. Highlights are rendered as HTML, so a request naming its own
+// markers could otherwise inject markup into the page.
+var highlightTags = map[string]string{
+	"<mark>":   "</mark>",
+	"<b>":      "</b>",
+	"<strong>": "</strong>",
+	"<em>":     "</em>",
+}
+
+// highlightMarkers validates the highlight markers requested with hl_start
+// and hl_stop.
+func highlightMarkers(start, stop string) (media.HighlightMarkers, error) {
+	if closing, ok := highlightTags[start]; !ok || closing != stop {
+		return media.HighlightMarkers{}, errors.New("hl_start and hl_stop must be one of <mark>, <b>, <strong> or <em> and its closing tag")
+	}
+	return media.HighlightMarkers{Start: start, Stop: stop}, nil
+}
+
+// queryList returns the non-empty values of a repeated or comma-separated
+// query parameter.
+func queryList(r *http.Request, key string) []string {
+	var values []string
+	for _, raw := range r.URL.Query()[key] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,36 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+func TestHighlightMarkers(t *testing.T) {
+	tests := []struct {
+		start, stop string
+		ok          bool
+	}{
+		{"<mark>", "</mark>", true},
+		{"<b>", "</b>", true},
+		{"<em>", "</em>", true},
+		{"<b>", "</mark>", false},
+		{"<mark>", "", false},
+		{"", "</mark>", false},
+		{"[", "]", false},
+		{`<img src=x onerror=alert(1)>`, "", false},
+		{"<MARK>", "</MARK>", false},
+	}
+
+	for _, tt := range tests {
+		got, err := highlightMarkers(tt.start, tt.stop)
+		if tt.ok {
+			want := media.HighlightMarkers{Start: tt.start, Stop: tt.stop}
+			if err != nil || got != want {
+				t.Errorf("highlightMarkers(%q, %q) = %v, %v, want %v", tt.start, tt.stop, got, err, want)
+			}
+		} else if err == nil {
+			t.Errorf("highlightMarkers(%q, %q) accepted unsafe or unpaired markers", tt.start, tt.stop)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/your-org/ems/internal/media"
+)
+
+// Field is a media item attribute a query can filter on.
+type Field string
+
+const (
+	FieldType    Field = "type"
+	FieldStatus  Field = "status"
+	FieldGenre   Field = "genre"
+	FieldCreator Field = "creator"
+	FieldTitle   Field = "title"
+	FieldYear    Field = "year"
+	FieldRating  Field = "rating"
+)
+
+// numeric reports whether the field accepts comparisons and ranges.
+func (f Field) numeric() bool {
+	return f == FieldYear || f == FieldRating
+}
+
+var knownFields = map[string]Field{
+	"type":    FieldType,
+	"status":  FieldStatus,
+	"genre":   FieldGenre,
+	"creator": FieldCreator,
+	"title":   FieldTitle,
+	"year":    FieldYear,
+	"rating":  FieldRating,
+}
+
+// Op is how a field term compares the field with its value.
+type Op string
+
+const (
+	OpMatch Op = ":"
+	OpGt    Op = ">"
+	OpGte   Op = ">="
+	OpLt    Op = "<"
+	OpLte   Op = "<="
+	OpRange Op = ".."
+)
+
+// Query is a parsed search query. All of its terms must hold.
+type Query struct {
+	Terms []Term
+}
+
+// Term is one element of a Query.
+type Term interface {
+	// Position is the rune offset of the term in the query string.
+	Position() int
+	// Negated reports whether the term was prefixed with '-'.
+	Negated() bool
+}
+
+// TextTerm matches free text. A phrase must match its words in order.
+type TextTerm struct {
+	Pos    int
+	Neg    bool
+	Text   string
+	Phrase bool
+}
+
+// FieldTerm restricts one field. Text fields use Value; numeric fields use
+// Number, or Low and High for OpRange, where a nil bound is open.
+type FieldTerm struct {
+	Pos    int
+	Neg    bool
+	Field  Field
+	Op     Op
+	Value  string
+	Number float64
+	Low    *float64
+	High   *float64
+}
+
+func (t *TextTerm) Position() int  { return t.Pos }
+func (t *TextTerm) Negated() bool  { return t.Neg }
+func (t *FieldTerm) Position() int { return t.Pos }
+func (t *FieldTerm) Negated() bool { return t.Neg }
+
+// SyntaxError describes an invalid query. Pos is the rune offset at which
+// the problem was found.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses a search query such as
+//
+//	type:game status:wishlist year:2015..2020 rating:>=8 genre:rpg "exact phrase" -creator:ubisoft
+//
+// Bare words and quoted phrases are free text; field:value pairs filter on
+// a field, and a leading '-' negates a term. A word ending in a colon that
+// does not name a field, as in "Halo: Reach", is free text.
+func Parse(input string) (*Query, error) {
+	p := &parser{src: []rune(input)}
+	q := &Query{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			return q, nil
+		}
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, term)
+	}
+}
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.src) }
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// term parses one optionally negated phrase, field term or word.
+func (p *parser) term() (Term, error) {
+	start := p.pos
+	neg := false
+	if p.src[p.pos] == '-' && p.pos+1 < len(p.src) && !unicode.IsSpace(p.src[p.pos+1]) {
+		neg = true
+		p.pos++
+	}
+
+	if p.src[p.pos] == '"' {
+		text, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, p.errorf(start, "empty phrase")
+		}
+		return &TextTerm{Pos: start, Neg: neg, Text: text, Phrase: true}, nil
+	}
+
+	wordStart := p.pos
+	for !p.done() && !unicode.IsSpace(p.src[p.pos]) && p.src[p.pos] != ':' && p.src[p.pos] != '"' {
+		p.pos++
+	}
+	word := string(p.src[wordStart:p.pos])
+
+	if !p.done() && p.src[p.pos] == ':' {
+		if field, ok := knownFields[strings.ToLower(word)]; ok {
+			p.pos++
+			return p.fieldTerm(start, neg, field)
+		}
+		// Not a field, as in "Halo: Reach": the colon is part of the text.
+		for !p.done() && !unicode.IsSpace(p.src[p.pos]) && p.src[p.pos] != '"' {
+			p.pos++
+		}
+		word = string(p.src[wordStart:p.pos])
+	}
+	if !p.done() && p.src[p.pos] == '"' {
+		return nil, p.errorf(p.pos, "unexpected quote")
+	}
+	return &TextTerm{Pos: start, Neg: neg, Text: word}, nil
+}
+
+// quoted reads a double-quoted string starting at the current position.
+func (p *parser) quoted() (string, error) {
+	open := p.pos
+	p.pos++
+	var b strings.Builder
+	for !p.done() {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			b.WriteRune(p.src[p.pos+1])
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteRune(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf(open, "unterminated quote")
+}
+
+// value reads a field value: a quoted string or a run of non-space runes.
+func (p *parser) value() (string, int, error) {
+	start := p.pos
+	if !p.done() && p.src[p.pos] == '"' {
+		v, err := p.quoted()
+		return v, start, err
+	}
+	for !p.done() && !unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos]), start, nil
+}
+
+func (p *parser) fieldTerm(start int, neg bool, field Field) (Term, error) {
+	raw, valuePos, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, p.errorf(valuePos, "missing value for %s", field)
+	}
+	t := &FieldTerm{Pos: start, Neg: neg, Field: field, Op: OpMatch}
+
+	if !field.numeric() {
+		t.Value = raw
+		switch field {
+		case FieldType:
+			mt := media.MediaType(strings.ToLower(raw))
+			if mt != media.MediaTypeMovie && mt != media.MediaTypeMusic && mt != media.MediaTypeGame {
+				return nil, p.errorf(valuePos, "type must be movie, music or game")
+			}
+			t.Value = string(mt)
+		case FieldStatus:
+			st := media.Status(strings.ToLower(raw))
+			switch st {
+			case media.StatusOwned, media.StatusWishlist, media.StatusCurrentlyUsing, media.StatusCompleted:
+			default:
+				return nil, p.errorf(valuePos, "status must be owned, wishlist, currently_using or completed")
+			}
+			t.Value = string(st)
+		}
+		return t, nil
+	}
+
+	if lo, hi, ok := strings.Cut(raw, ".."); ok {
+		t.Op = OpRange
+		if lo == "" && hi == "" {
+			return nil, p.errorf(valuePos, "range needs at least one bound")
+		}
+		if lo != "" {
+			n, err := p.number(field, lo, valuePos)
+			if err != nil {
+				return nil, err
+			}
+			t.Low = &n
+		}
+		if hi != "" {
+			n, err := p.number(field, hi, valuePos+len([]rune(lo))+2)
+			if err != nil {
+				return nil, err
+			}
+			t.High = &n
+		}
+		if t.Low != nil && t.High != nil && *t.Low > *t.High {
+			return nil, p.errorf(valuePos, "range start is after its end")
+		}
+		return t, nil
+	}
+
+	numPos := valuePos
+	for _, op := range []Op{OpGte, OpLte, OpGt, OpLt} {
+		if strings.HasPrefix(raw, string(op)) {
+			t.Op = op
+			raw = raw[len(op):]
+			numPos += len(op)
+			break
+		}
+	}
+	if t.Op == OpMatch && strings.HasPrefix(raw, "=") {
+		raw = raw[1:]
+		numPos++
+	}
+	n, err := p.number(field, raw, numPos)
+	if err != nil {
+		return nil, err
+	}
+	t.Number = n
+	return t, nil
+}
+
+// number parses a numeric field value; years must be whole numbers.
+func (p *parser) number(field Field, s string, pos int) (float64, error) {
+	if field == FieldYear {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, p.errorf(pos, "year must be a whole number, got %q", s)
+		}
+		return float64(n), nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, p.errorf(pos, "%s must be a number, got %q", field, s)
+	}
+	if field == FieldRating && (n < 0 || n > 10) {
+		return 0, p.errorf(pos, "rating must be between 0 and 10")
+	}
+	return n, nil
+}
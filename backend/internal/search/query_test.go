@@ -0,0 +1,244 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr(n float64) *float64 { return &n }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Term
+	}{
+		{
+			name:  "empty",
+			input: "   ",
+			want:  nil,
+		},
+		{
+			name:  "words",
+			input: "star wars",
+			want: []Term{
+				&TextTerm{Pos: 0, Text: "star"},
+				&TextTerm{Pos: 5, Text: "wars"},
+			},
+		},
+		{
+			name:  "phrase with escaped quote",
+			input: `"say \"hi\""`,
+			want:  []Term{&TextTerm{Pos: 0, Text: `say "hi"`, Phrase: true}},
+		},
+		{
+			name:  "negated word and phrase",
+			input: `-sequel -"director's cut"`,
+			want: []Term{
+				&TextTerm{Pos: 0, Neg: true, Text: "sequel"},
+				&TextTerm{Pos: 8, Neg: true, Text: "director's cut", Phrase: true},
+			},
+		},
+		{
+			name:  "lone dash is text",
+			input: "spider - man",
+			want: []Term{
+				&TextTerm{Pos: 0, Text: "spider"},
+				&TextTerm{Pos: 7, Text: "-"},
+				&TextTerm{Pos: 9, Text: "man"},
+			},
+		},
+		{
+			name:  "unknown field is text",
+			input: "Star Wars: A New Hope",
+			want: []Term{
+				&TextTerm{Pos: 0, Text: "Star"},
+				&TextTerm{Pos: 5, Text: "Wars:"},
+				&TextTerm{Pos: 11, Text: "A"},
+				&TextTerm{Pos: 13, Text: "New"},
+				&TextTerm{Pos: 17, Text: "Hope"},
+			},
+		},
+		{
+			name:  "unknown field with value is text",
+			input: "re:zero",
+			want:  []Term{&TextTerm{Pos: 0, Text: "re:zero"}},
+		},
+		{
+			name:  "text fields",
+			input: `Type:GAME status:wishlist genre:rpg creator:"From Software" title:ring`,
+			want: []Term{
+				&FieldTerm{Pos: 0, Field: FieldType, Op: OpMatch, Value: "game"},
+				&FieldTerm{Pos: 10, Field: FieldStatus, Op: OpMatch, Value: "wishlist"},
+				&FieldTerm{Pos: 26, Field: FieldGenre, Op: OpMatch, Value: "rpg"},
+				&FieldTerm{Pos: 36, Field: FieldCreator, Op: OpMatch, Value: "From Software"},
+				&FieldTerm{Pos: 60, Field: FieldTitle, Op: OpMatch, Value: "ring"},
+			},
+		},
+		{
+			name:  "negated field",
+			input: "-creator:ubisoft",
+			want:  []Term{&FieldTerm{Pos: 0, Neg: true, Field: FieldCreator, Op: OpMatch, Value: "ubisoft"}},
+		},
+		{
+			name:  "comparisons",
+			input: "rating:>=8 year:<2000 rating:>7.5 year:<=1999 year:=2001 year:1984",
+			want: []Term{
+				&FieldTerm{Pos: 0, Field: FieldRating, Op: OpGte, Number: 8},
+				&FieldTerm{Pos: 11, Field: FieldYear, Op: OpLt, Number: 2000},
+				&FieldTerm{Pos: 22, Field: FieldRating, Op: OpGt, Number: 7.5},
+				&FieldTerm{Pos: 34, Field: FieldYear, Op: OpLte, Number: 1999},
+				&FieldTerm{Pos: 46, Field: FieldYear, Op: OpMatch, Number: 2001},
+				&FieldTerm{Pos: 57, Field: FieldYear, Op: OpMatch, Number: 1984},
+			},
+		},
+		{
+			name:  "ranges",
+			input: "year:2015..2020 rating:7.. year:..1990",
+			want: []Term{
+				&FieldTerm{Pos: 0, Field: FieldYear, Op: OpRange, Low: ptr(2015), High: ptr(2020)},
+				&FieldTerm{Pos: 16, Field: FieldRating, Op: OpRange, Low: ptr(7)},
+				&FieldTerm{Pos: 27, Field: FieldYear, Op: OpRange, High: ptr(1990)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(q.Terms, tt.want) {
+				t.Errorf("Parse(%q) terms:\n got %s\nwant %s", tt.input, dumpTerms(q.Terms), dumpTerms(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{`"unterminated`, 0, "unterminated quote"},
+		{`title:"open`, 6, "unterminated quote"},
+		{`""`, 0, "empty phrase"},
+		{`foo"bar"`, 3, "unexpected quote"},
+		{`type: game`, 5, "missing value for type"},
+		{`type:book`, 5, "type must be movie, music or game"},
+		{`status:lent`, 7, "status must be"},
+		{`year:20x5`, 5, "year must be a whole number"},
+		{`year:2020.5`, 5, "year must be a whole number"},
+		{`rating:high`, 7, "rating must be a number"},
+		{`rating:NaN`, 7, "rating must be a number"},
+		{`rating:>Inf`, 8, "rating must be a number"},
+		{`rating:11`, 7, "rating must be between 0 and 10"},
+		{`rating:>=-1`, 9, "rating must be between 0 and 10"},
+		{`year:..`, 5, "range needs at least one bound"},
+		{`year:2000..19x0`, 11, "year must be a whole number"},
+		{`year:2020..2010`, 5, "range start is after its end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want *SyntaxError", tt.input, err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error position = %d, want %d (%s)", tt.input, syntaxErr.Pos, tt.pos, syntaxErr.Msg)
+			}
+			if !strings.Contains(syntaxErr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.input, syntaxErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input string
+		text  string
+		where string
+		args  []any
+	}{
+		{
+			input: "",
+			text:  "",
+			where: "",
+			args:  nil,
+		},
+		{
+			input: "dark souls",
+			text:  "dark souls",
+			where: "",
+			args:  nil,
+		},
+		{
+			input: `"dark souls" type:game`,
+			text:  "dark souls",
+			where: "(search_vector @@ phraseto_tsquery('english', ?) OR (notes_readable AND notes_vector @@ phraseto_tsquery('english', ?)) OR title ILIKE ? OR creator ILIKE ?) AND media_type::text = ?",
+			args:  []any{"dark souls", "dark souls", "%dark souls%", "%dark souls%", "game"},
+		},
+		{
+			input: "-remaster genre:rpg",
+			text:  "",
+			where: "NOT COALESCE((search_vector @@ plainto_tsquery('english', ?) OR (notes_readable AND notes_vector @@ plainto_tsquery('english', ?)) OR title ILIKE ? OR creator ILIKE ?), false) AND EXISTS (SELECT 1 FROM unnest(genre) AS g WHERE g ILIKE ?)",
+			args:  []any{"remaster", "remaster", "%remaster%", "%remaster%", "%rpg%"},
+		},
+		{
+			input: "creator:100%_sure",
+			text:  "",
+			where: "creator ILIKE ?",
+			args:  []any{`%100\%\_sure%`},
+		},
+		{
+			input: "year:1990..1999 rating:>=8 -rating:<5 year:..2000 rating:6..",
+			text:  "",
+			where: "release_year BETWEEN ? AND ? AND rating >= ? AND NOT COALESCE(rating < ?, false) AND release_year <= ? AND rating >= ?",
+			args:  []any{1990, 1999, 8.0, 5.0, 2000, 6.0},
+		},
+		{
+			input: "year:2001 status:completed",
+			text:  "",
+			where: "release_year = ? AND status::text = ?",
+			args:  []any{2001, "completed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			c := q.Compile()
+			if c.Text != tt.text {
+				t.Errorf("Text = %q, want %q", c.Text, tt.text)
+			}
+			if c.Where != tt.where {
+				t.Errorf("Where =\n %s\nwant\n %s", c.Where, tt.where)
+			}
+			if !reflect.DeepEqual(c.Args, tt.args) {
+				t.Errorf("Args = %#v, want %#v", c.Args, tt.args)
+			}
+			if n := strings.Count(c.Where, "?"); n != len(c.Args) {
+				t.Errorf("%d placeholders for %d args", n, len(c.Args))
+			}
+		})
+	}
+}
+
+func dumpTerms(terms []Term) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = fmt.Sprintf("%+v", t)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,162 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/httputil"
+	"github.com/your-org/ems/internal/media"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxSuggestions caps the suggestions returned per kind.
+const maxSuggestions = 20
+
+// ExternalSuggestion is a title suggested by a metadata provider.
+type ExternalSuggestion struct {
+	Title       string          `json:"title"`
+	Creator     string          `json:"creator,omitempty"`
+	MediaType   media.MediaType `json:"media_type"`
+	ReleaseYear int             `json:"release_year,omitempty"`
+	ExternalID  string          `json:"external_id"`
+	CoverURL    string          `json:"cover_url,omitempty"`
+}
+
+// SuggestResponse groups suggestions by kind. TimedOut lists the kinds that
+// missed their latency budget and were left out.
+type SuggestResponse struct {
+	Query    string                                      `json:"query"`
+	Groups   map[media.SuggestionKind][]media.Suggestion `json:"groups"`
+	External []ExternalSuggestion                        `json:"external,omitempty"`
+	TimedOut []string                                    `json:"timed_out,omitempty"`
+}
+
+// Suggest handles GET /api/search/suggest. It completes q against the
+// user's titles, creators, genres and tags, each lookup bounded by the
+// suggestion timeout. kind restricts the groups returned and limit sets the
+// size of each. With external=true, metadata provider titles for type (or
+// every media type) are included within their own, longer budget.
+func (h *Handler) Suggest(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "query parameter 'q' is required")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 5
+	}
+	limit = min(limit, maxSuggestions)
+
+	kinds := media.SuggestionKinds
+	if requested := queryList(r, "kind"); len(requested) > 0 {
+		kinds = nil
+		for _, k := range requested {
+			kind := media.SuggestionKind(k)
+			if !validKind(kind) {
+				httputil.WriteError(w, http.StatusBadRequest, "invalid kind: "+k)
+				return
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	var mediaTypes []media.MediaType
+	if r.URL.Query().Get("external") == "true" && h.meta != nil {
+		mediaTypes = []media.MediaType{media.MediaTypeMovie, media.MediaTypeMusic, media.MediaTypeGame}
+		if t := r.URL.Query().Get("type"); t != "" {
+			mediaTypes = []media.MediaType{media.MediaType(t)}
+		}
+	}
+
+	resp := SuggestResponse{
+		Query:  q,
+		Groups: make(map[media.SuggestionKind][]media.Suggestion, len(kinds)),
+	}
+	external := make(map[media.MediaType][]ExternalSuggestion, len(mediaTypes))
+	var mu sync.Mutex
+	timedOut := func(name string) {
+		mu.Lock()
+		resp.TimedOut = append(resp.TimedOut, name)
+		mu.Unlock()
+	}
+
+	g, gCtx := errgroup.WithContext(r.Context())
+	for _, kind := range kinds {
+		kind := kind
+		g.Go(func() error {
+			ctx, cancel := context.WithTimeout(gCtx, h.cfg.SuggestTimeout)
+			defer cancel()
+
+			suggestions, err := h.repo.Suggest(ctx, claims.UserID, kind, q, limit)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					timedOut(string(kind))
+					suggestions = []media.Suggestion{}
+				} else {
+					return err
+				}
+			}
+			mu.Lock()
+			resp.Groups[kind] = suggestions
+			mu.Unlock()
+			return nil
+		})
+	}
+	for _, mt := range mediaTypes {
+		mt := mt
+		g.Go(func() error {
+			ctx, cancel := context.WithTimeout(gCtx, h.cfg.ExternalSuggestTimeout)
+			defer cancel()
+
+			results, err := h.meta.Search(ctx, q, mt, nil)
+			if err != nil {
+				// Provider suggestions are best effort.
+				if errors.Is(err, context.DeadlineExceeded) {
+					timedOut("external:" + string(mt))
+				}
+				return nil
+			}
+			suggestions := make([]ExternalSuggestion, 0, limit)
+			for _, res := range results[:min(len(results), limit)] {
+				suggestions = append(suggestions, ExternalSuggestion{
+					Title:       res.Title,
+					Creator:     res.Creator,
+					MediaType:   mt,
+					ReleaseYear: res.ReleaseYear,
+					ExternalID:  res.ExternalID,
+					CoverURL:    res.CoverURL,
+				})
+			}
+			mu.Lock()
+			external[mt] = suggestions
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, mt := range mediaTypes {
+		resp.External = append(resp.External, external[mt]...)
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+func validKind(kind media.SuggestionKind) bool {
+	for _, k := range media.SuggestionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
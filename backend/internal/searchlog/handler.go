@@ -0,0 +1,64 @@
+package searchlog
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/your-org/ems/internal/auth"
+	"github.com/your-org/ems/internal/httputil"
+)
+
+// Handler handles HTTP requests for search history and analytics.
+type Handler struct {
+	repo *Repository
+}
+
+// NewHandler creates a new searchlog Handler.
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// History handles GET /api/search/history.
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	entries, err := h.repo.ListRecent(r.Context(), claims.UserID, min(limit, 100))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, entries)
+}
+
+// ClearHistory handles DELETE /api/search/history.
+func (h *Handler) ClearHistory(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromCtx(r.Context())
+	if err := h.repo.DeleteForUser(r.Context(), claims.UserID); err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ZeroResults handles GET /api/admin/search/zero-results.
+func (h *Handler) ZeroResults(w http.ResponseWriter, r *http.Request) {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 {
+		days = 30
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	since := time.Now().AddDate(0, 0, -days)
+	queries, err := h.repo.TopZeroResults(r.Context(), since, min(limit, 100))
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"since":              since,
+		"min_distinct_users": MinDistinctUsers,
+		"queries":            queries,
+	})
+}
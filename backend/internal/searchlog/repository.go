@@ -0,0 +1,138 @@
+package searchlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Privacy limits for recorded queries.
+const (
+	// maxQueryLength truncates stored queries, in runes.
+	maxQueryLength = 200
+	// MinDistinctUsers is how many different users must have run a query
+	// before it appears in aggregates, so no single user's searches are
+	// exposed to administrators.
+	MinDistinctUsers = 3
+)
+
+// Repository handles search query persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new searchlog Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// normalize lowercases a query, collapses whitespace and truncates it so
+// equivalent searches aggregate together.
+func normalize(query string) string {
+	q := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if runes := []rune(q); len(runes) > maxQueryLength {
+		q = string(runes[:maxQueryLength])
+	}
+	return q
+}
+
+// Record stores a search the user ran. Empty queries are ignored.
+func (r *Repository) Record(ctx context.Context, userID uuid.UUID, source Source, query string, resultCount int, latency time.Duration) error {
+	q := normalize(query)
+	if q == "" {
+		return nil
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO search_queries (user_id, source, query, result_count, latency_ms)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, source, q, resultCount, latency.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("insert search query: %w", err)
+	}
+	return nil
+}
+
+// ListRecent returns the user's most recent distinct searches, newest first.
+func (r *Repository) ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*Entry, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT query, source, result_count, latency_ms, created_at FROM (
+			SELECT DISTINCT ON (query, source) query, source, result_count, latency_ms, created_at
+			FROM search_queries
+			WHERE user_id = $1
+			ORDER BY query, source, created_at DESC
+		) AS latest
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list searches: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Query, &e.Source, &e.ResultCount, &e.LatencyMS, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan search: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteForUser removes the user's search history.
+func (r *Repository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, "DELETE FROM search_queries WHERE user_id=$1", userID); err != nil {
+		return fmt.Errorf("delete searches: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore removes searches recorded before the cutoff and returns how
+// many were removed.
+func (r *Repository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM search_queries WHERE created_at < $1", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge searches: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// TopZeroResults returns the queries that most often found nothing since the
+// given time. Only queries run by at least MinDistinctUsers users are
+// included, and no user identities are returned.
+func (r *Repository) TopZeroResults(ctx context.Context, since time.Time, limit int) ([]*ZeroResultQuery, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT query, COUNT(*), COUNT(DISTINCT user_id), MAX(created_at)
+		FROM search_queries
+		WHERE result_count = 0 AND created_at >= $1
+		GROUP BY query
+		HAVING COUNT(DISTINCT user_id) >= $2
+		ORDER BY COUNT(*) DESC, query
+		LIMIT $3
+	`, since, MinDistinctUsers, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query zero-result searches: %w", err)
+	}
+	defer rows.Close()
+
+	queries := make([]*ZeroResultQuery, 0)
+	for rows.Next() {
+		var q ZeroResultQuery
+		if err := rows.Scan(&q.Query, &q.Searches, &q.Users, &q.LastSeen); err != nil {
+			return nil, fmt.Errorf("scan zero-result search: %w", err)
+		}
+		queries = append(queries, &q)
+	}
+	return queries, rows.Err()
+}
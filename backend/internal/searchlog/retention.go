@@ -0,0 +1,29 @@
+package searchlog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunRetention deletes searches older than retention once at start and then
+// every interval until ctx is cancelled.
+func RunRetention(ctx context.Context, repo *Repository, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := repo.DeleteBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			slog.Warn("search log retention failed", "error", err)
+		} else if n > 0 {
+			slog.Info("search log retention", "deleted", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package searchlog records search queries for per-user history and
+// anonymous aggregate analytics.
+package searchlog
+
+import "time"
+
+// Source identifies the search endpoint a query came from.
+type Source string
+
+const (
+	SourceSearch   Source = "search"
+	SourceNLSearch Source = "nl_search"
+)
+
+// Entry is one search the user ran.
+type Entry struct {
+	Query       string    `json:"query"`
+	Source      Source    `json:"source"`
+	ResultCount int       `json:"result_count"`
+	LatencyMS   int       `json:"latency_ms"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ZeroResultQuery is a query that found nothing, aggregated across users.
+type ZeroResultQuery struct {
+	Query    string    `json:"query"`
+	Searches int       `json:"searches"`
+	Users    int       `json:"users"`
+	LastSeen time.Time `json:"last_seen"`
+}
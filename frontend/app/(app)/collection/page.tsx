@@ -7,13 +7,27 @@ import { MediaCardSkeleton } from '@/components/ui/Skeleton'
 import { fetchMediaList, searchMedia } from '@/lib/api/media'
 import type { MediaType, MediaStatus } from '@/lib/types'
 
+interface CollectionParams {
+  type?: string
+  status?: string
+  q?: string
+  genre?: string
+  creator?: string
+  year_min?: string
+  year_max?: string
+  rating_min?: string
+  text?: string
+  page?: string
+}
+
 interface PageProps {
-  searchParams: Promise<{
-    type?: string
-    status?: string
-    q?: string
-    page?: string
-  }>
+  searchParams: Promise<CollectionParams>
+}
+
+function optionalNumber(v?: string): number | undefined {
+  if (!v) return undefined
+  const n = Number(v)
+  return Number.isFinite(n) ? n : undefined
 }
 
 export default async function CollectionPage({ searchParams }: PageProps) {
@@ -50,7 +64,7 @@ async function CollectionContent({
   params,
 }: {
   token: string
-  params: { type?: string; status?: string; q?: string; page?: string }
+  params: CollectionParams
 }) {
   const page = params.page ? parseInt(params.page) : 1
 
@@ -60,6 +74,12 @@ async function CollectionContent({
     : await fetchMediaList(token, {
         type: params.type as MediaType | undefined,
         status: params.status as MediaStatus | undefined,
+        genre: params.genre,
+        creator: params.creator,
+        year_min: optionalNumber(params.year_min),
+        year_max: optionalNumber(params.year_max),
+        rating_min: optionalNumber(params.rating_min),
+        text: params.text,
         page,
         page_size: 24,
       })
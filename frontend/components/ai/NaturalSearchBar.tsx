@@ -20,12 +20,18 @@ export function NaturalSearchBar({ token }: Props) {
     setLoading(true)
     try {
       const result = await nlSearch(token, query)
-      // Apply filters from NL parsing as query params
+      // Apply the interpreted filters as collection query params
+      const { filters } = result
       const qs = new URLSearchParams()
-      if (result.filters.type) qs.set('type', String(result.filters.type))
-      if (result.filters.status) qs.set('status', String(result.filters.status))
-      if (result.filters.q) qs.set('q', String(result.filters.q))
-      else qs.set('q', query)
+      if (filters.media_type) qs.set('type', filters.media_type)
+      if (filters.status) qs.set('status', filters.status)
+      if (filters.genre) qs.set('genre', filters.genre)
+      if (filters.creator) qs.set('creator', filters.creator)
+      if (filters.release_year_min != null) qs.set('year_min', String(filters.release_year_min))
+      if (filters.release_year_max != null) qs.set('year_max', String(filters.release_year_max))
+      if (filters.rating_min != null) qs.set('rating_min', String(filters.rating_min))
+      // Plain text within the filtered list, not the /api/search query language
+      if (filters.text_query) qs.set('text', filters.text_query)
       router.push(`/collection?${qs.toString()}`)
     } catch {
       // Fall back to plain text search
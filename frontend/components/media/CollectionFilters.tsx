@@ -25,7 +25,11 @@ export function CollectionFilters() {
 
   const currentType = searchParams.get('type') ?? ''
   const currentStatus = searchParams.get('status') ?? ''
-  const hasFilters = currentType || currentStatus
+  // Natural language searches add filters that have no control here
+  const hasFilters =
+    currentType ||
+    currentStatus ||
+    ['genre', 'creator', 'year_min', 'year_max', 'rating_min', 'text'].some((k) => searchParams.has(k))
 
   function updateParam(key: string, value: string) {
     const params = new URLSearchParams(searchParams.toString())
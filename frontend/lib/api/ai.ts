@@ -7,13 +7,32 @@ export async function fetchRecommendations(token: string): Promise<Recommendatio
   return apiFetch<Recommendation[]>('/api/ai/recommendations', { token })
 }
 
+export interface NLSearchFilters {
+  media_type: string | null
+  status: string | null
+  genre: string | null
+  creator: string | null
+  release_year_min: number | null
+  release_year_max: number | null
+  rating_min: number | null
+  text_query: string | null
+}
+
+// Pass the filters of an earlier response to fetch another page without
+// interpreting the query again.
 export async function nlSearch(
   token: string,
   query: string,
-): Promise<{ query: string; filters: Record<string, unknown> }> {
+  opts: { filters?: NLSearchFilters; page?: number } = {},
+): Promise<{
+  query: string
+  filters: NLSearchFilters
+  total: number
+  page: number
+}> {
   return apiFetch('/api/ai/nl-search', {
     method: 'POST',
-    body: JSON.stringify({ query }),
+    body: JSON.stringify({ query, ...opts }),
     token,
   })
 }
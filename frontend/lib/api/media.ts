@@ -5,6 +5,12 @@ export interface MediaListParams {
   type?: MediaType
   status?: MediaStatus
   genre?: string
+  creator?: string
+  year_min?: number
+  year_max?: number
+  rating_min?: number
+  /** Plain text matched against title, creator and the search index. */
+  text?: string
   page?: number
   page_size?: number
 }
@@ -17,6 +23,11 @@ export async function fetchMediaList(
   if (params.type) qs.set('type', params.type)
   if (params.status) qs.set('status', params.status)
   if (params.genre) qs.set('genre', params.genre)
+  if (params.creator) qs.set('creator', params.creator)
+  if (params.year_min != null) qs.set('year_min', String(params.year_min))
+  if (params.year_max != null) qs.set('year_max', String(params.year_max))
+  if (params.rating_min != null) qs.set('rating_min', String(params.rating_min))
+  if (params.text) qs.set('q', params.text)
   if (params.page) qs.set('page', String(params.page))
   if (params.page_size) qs.set('page_size', String(params.page_size))
 